@@ -2,40 +2,31 @@ package api
 
 import (
 	"database/sql"
-	"errors"
-	"log"
+	"simple_bank/apierror"
 	db "simple_bank/db/sqlc"
 	"simple_bank/token"
-	"strconv"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
-	"github.com/lib/pq"
 )
 
 type createAccountReq struct {
 	// Owner    string `json:"owner" validate:"required"`
-	Currency string `json:"currency" validate:"required,oneof=KRW USD EUR"`
+	Currency string `json:"currency" validate:"required,currency"`
 }
 
 func (server *Server) createAccount(ctx *fiber.Ctx) error {
 
 	req := new(createAccountReq)
 
-	if err := ctx.BodyParser(req); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
-	}
-
-	validate := validator.New()
-	if err := validate.Struct(req); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
 	}
 
 	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
 
 	if authPayload.ID == uuid.Nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(errors.New("invalid token payload")))
+		return apierror.New(fiber.StatusBadRequest, apierror.CodeInvalidToken, "invalid token payload")
 	}
 
 	arg := db.CreateAccountParams{
@@ -44,55 +35,112 @@ func (server *Server) createAccount(ctx *fiber.Ctx) error {
 		Balance:  0,
 	}
 
-	account, err := server.store.CreateAccount(ctx.Context(), arg)
+	account, err := server.store.CreateAccountTx(ctx.Context(), arg)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			log.Println(pqErr.Code.Name())
-			switch pqErr.Code.Name() {
-			case "foreign_key_violation", "unique_violation":
-				return ctx.Status(fiber.StatusForbidden).JSON(errorResponse(err))
-			}
-		}
-		return ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return err
 	}
 
-	return ctx.JSON(account)
+	return sendAccount(ctx, account)
 }
 
 type getAccountReq struct {
-	ID int64 `validate:"required,number"`
+	ID int64 `params:"id" validate:"required,number"`
 }
 
-func (server *Server) getAccount(ctx *fiber.Ctx) error {
+type accountResponse struct {
+	db.Account
+	// AvailableBalance is the balance minus the funds reserved by active holds
+	AvailableBalance int64            `json:"available_balance"`
+	Formatted        formattedAmounts `json:"formatted,omitempty"`
+}
+
+func newAccountResponse(account db.Account, held int64) accountResponse {
+	return accountResponse{
+		Account:          account,
+		AvailableBalance: account.Balance - held,
+	}
+}
+
+// formattedAccount is an account along with its amounts formatted, if the client asked for them
+type formattedAccount struct {
+	db.Account
+	Formatted formattedAmounts `json:"formatted,omitempty"`
+}
+
+func newFormattedAccount(ctx *fiber.Ctx, account db.Account) (formattedAccount, error) {
+	rsp := formattedAccount{Account: account}
+	if !wantsFormattedAmounts(ctx) {
+		return rsp, nil
+	}
+
 	var err error
-	req := new(getAccountReq)
+	rsp.Formatted, err = formatAmounts(account.Currency, map[string]int64{
+		"balance":         account.Balance,
+		"overdraft_limit": account.OverdraftLimit,
+	})
+	return rsp, err
+}
 
-	req.ID, err = strconv.ParseInt(ctx.Params("id"), 10, 64)
+func sendAccount(ctx *fiber.Ctx, account db.Account) error {
+	rsp, err := newFormattedAccount(ctx, account)
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return err
 	}
+	return ctx.JSON(rsp)
+}
 
-	validate := validator.New()
-	if err = validate.Struct(req); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+func sendAccounts(ctx *fiber.Ctx, accounts []db.Account) error {
+	rsp := make([]formattedAccount, 0, len(accounts))
+	for _, account := range accounts {
+		formatted, err := newFormattedAccount(ctx, account)
+		if err != nil {
+			return err
+		}
+		rsp = append(rsp, formatted)
+	}
+	return ctx.JSON(rsp)
+}
+
+func (server *Server) getAccount(ctx *fiber.Ctx) error {
+	req := new(getAccountReq)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
 	}
 
 	account, err := server.store.GetAccount(ctx.Context(), req.ID)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return errAccountNotFound
 		}
-		return ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return err
 	}
 
 	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
 
-	if authPayload.Username == account.Owner {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(errors.New("account doesn't belongs to the authenticated user")))
+	if authPayload.Username != account.Owner && !isStaff(authPayload) {
+		return errAccountNotOwned
+	}
+
+	held, err := server.store.GetHeldAmount(ctx.Context(), account.ID)
+	if err != nil {
+		return err
+	}
+
+	rsp := newAccountResponse(account, held)
+	if wantsFormattedAmounts(ctx) {
+		rsp.Formatted, err = formatAmounts(account.Currency, map[string]int64{
+			"balance":           account.Balance,
+			"available_balance": rsp.AvailableBalance,
+			"overdraft_limit":   account.OverdraftLimit,
+		})
+		if err != nil {
+			return err
+		}
 	}
 
-	return ctx.JSON(account)
+	return ctx.JSON(rsp)
 }
 
 type listAccountsReq struct {
@@ -103,18 +151,13 @@ type listAccountsReq struct {
 func (server *Server) listAccounts(ctx *fiber.Ctx) error {
 	req := new(listAccountsReq)
 
-	if err := ctx.QueryParser(req); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
-	}
-
-	validate := validator.New()
-	if err := validate.Struct(req); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
 	}
 	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
 
 	if authPayload.ID == uuid.Nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(errors.New("invalid token payload")))
+		return apierror.New(fiber.StatusBadRequest, apierror.CodeInvalidToken, "invalid token payload")
 	}
 
 	arg := db.ListAccountsParams{
@@ -125,33 +168,48 @@ func (server *Server) listAccounts(ctx *fiber.Ctx) error {
 
 	accounts, err := server.store.ListAccounts(ctx.Context(), arg)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return err
 	}
 
-	return ctx.JSON(accounts)
+	return sendAccounts(ctx, accounts)
 }
 
-type updateAccountReq struct {
-	ID      int64 `validate:"required,number,min=1"`
-	Balance int64 `json:"balance" validate:"required,number,min=0"`
+type listAllAccountsReq struct {
+	PageID   int32 `query:"page_id" validate:"required,number,min=1"`
+	PageSize int32 `query:"page_size" validate:"required,number,min=5,max=10"`
 }
 
-func (server *Server) updateAccount(ctx *fiber.Ctx) error {
-	var err error
-	req := new(updateAccountReq)
+// listAllAccounts lists the accounts of every customer, for tellers and admins
+func (server *Server) listAllAccounts(ctx *fiber.Ctx) error {
+	req := new(listAllAccountsReq)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
 
-	if err = ctx.BodyParser(req); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+	arg := db.GetAccountsParams{
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
 	}
 
-	req.ID, err = strconv.ParseInt(ctx.Params("id"), 10, 64)
+	accounts, err := server.store.GetAccounts(ctx.Context(), arg)
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+		return err
 	}
 
-	validate := validator.New()
-	if err = validate.Struct(req); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+	return sendAccounts(ctx, accounts)
+}
+
+type updateAccountReq struct {
+	ID      int64 `params:"id" validate:"required,number,min=1"`
+	Balance int64 `json:"balance" validate:"required,number,min=0"`
+}
+
+func (server *Server) updateAccount(ctx *fiber.Ctx) error {
+	req := new(updateAccountReq)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
 	}
 
 	arg := db.UpdateAccountParams{
@@ -162,10 +220,39 @@ func (server *Server) updateAccount(ctx *fiber.Ctx) error {
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return errAccountNotFound
+		}
+		return err
+	}
+
+	return sendAccount(ctx, account)
+}
+
+type updateOverdraftLimitReq struct {
+	ID             int64 `params:"id" validate:"required,number,min=1"`
+	OverdraftLimit int64 `json:"overdraft_limit" validate:"number,min=0"`
+}
+
+// updateOverdraftLimit sets how far below zero transfers and withdrawals may take the account
+func (server *Server) updateOverdraftLimit(ctx *fiber.Ctx) error {
+	req := new(updateOverdraftLimitReq)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	arg := db.UpdateAccountOverdraftLimitParams{
+		ID:             req.ID,
+		OverdraftLimit: req.OverdraftLimit,
+	}
+	account, err := server.store.UpdateAccountOverdraftLimit(ctx.Context(), arg)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errAccountNotFound
 		}
-		return ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return err
 	}
 
-	return ctx.JSON(account)
+	return sendAccount(ctx, account)
 }
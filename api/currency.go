@@ -0,0 +1,37 @@
+package api
+
+import (
+	"simple_bank/currency"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// listCurrencies lists the currencies accounts can be opened in
+func (server *Server) listCurrencies(ctx *fiber.Ctx) error {
+	return ctx.JSON(server.currencies.Currencies())
+}
+
+// formattedAmounts holds amounts formatted for display in their currency, keyed by the field they format
+type formattedAmounts map[string]string
+
+// wantsFormattedAmounts reports whether the client asked for formatted amounts with ?formatted_amounts=true
+func wantsFormattedAmounts(ctx *fiber.Ctx) bool {
+	formatted, _ := strconv.ParseBool(ctx.Query("formatted_amounts"))
+	return formatted
+}
+
+// formatAmounts formats the amounts of the given fields. Any known currency is formatted, so that
+// accounts keep their formatting when their currency is no longer supported.
+func formatAmounts(code string, amounts map[string]int64) (formattedAmounts, error) {
+	cur, err := currency.Lookup(code)
+	if err != nil {
+		return nil, err
+	}
+
+	formatted := make(formattedAmounts, len(amounts))
+	for field, amount := range amounts {
+		formatted[field] = currency.New(amount, cur).String()
+	}
+	return formatted, nil
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"simple_bank/currency"
+	mockdb "simple_bank/db/mock"
+	db "simple_bank/db/sqlc"
+	"simple_bank/revocation"
+	"simple_bank/util"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCurrenciesAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+
+	request := httptest.NewRequest(http.MethodGet, "/currencies", nil)
+	response, err := server.router.Test(request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	data, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	var currencies []currency.Currency
+	err = json.Unmarshal(data, &currencies)
+	require.NoError(t, err)
+	require.Len(t, currencies, len(currency.DefaultCodes))
+	for i, code := range currency.DefaultCodes {
+		require.Equal(t, code, currencies[i].Code)
+	}
+}
+
+func TestConfiguredCurrencies(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateAccountTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ interface{}, arg db.CreateAccountParams) (db.Account, error) {
+			return db.Account{ID: 1, Owner: arg.Owner, Currency: arg.Currency}, nil
+		})
+
+	config := util.Config{
+		TokenSymmetricKey:   util.RandomString(32),
+		AccessTokenDuration: time.Minute,
+		SupportedCurrencies: []string{"USD", "JPY"},
+	}
+	server, err := NewServer(config, store, revocation.NewList(store))
+	require.NoError(t, err)
+
+	createAccount := func(currency string) *http.Response {
+		data, err := json.Marshal(fiber.Map{"currency": currency})
+		require.NoError(t, err)
+
+		request := httptest.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(data))
+		request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+
+		response, err := server.router.Test(request)
+		require.NoError(t, err)
+		return response
+	}
+
+	require.Equal(t, http.StatusOK, createAccount("JPY").StatusCode)
+	// supported by default, but not configured
+	require.Equal(t, http.StatusBadRequest, createAccount("KRW").StatusCode)
+
+	_, err = NewServer(util.Config{
+		TokenSymmetricKey:   util.RandomString(32),
+		SupportedCurrencies: []string{"XYZ"},
+	}, store, revocation.NewList(store))
+	require.ErrorIs(t, err, currency.ErrUnknownCurrency)
+}
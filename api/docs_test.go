@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+var routeParamRegexp = regexp.MustCompile(`:([A-Za-z_]+)`)
+
+// undocumentedPaths are registered for humans or as middleware and are left out of the spec on purpose
+var undocumentedPaths = map[string]bool{
+	"/":     true,
+	"/docs": true,
+}
+
+type openAPIDocument struct {
+	OpenAPI string                                `json:"openapi"`
+	Paths   map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+func loadOpenAPISpec(t *testing.T) openAPIDocument {
+	var spec openAPIDocument
+	err := json.Unmarshal(openAPISpec, &spec)
+	require.NoError(t, err)
+	require.NotEmpty(t, spec.OpenAPI)
+	return spec
+}
+
+// registeredRoutes returns the method and OpenAPI path of every route in the router, e.g. "get /account/{id}"
+func registeredRoutes(app *fiber.App) map[string]bool {
+	routes := make(map[string]bool)
+	for _, stack := range app.Stack() {
+		for _, route := range stack {
+			// fiber registers a HEAD route for every GET route
+			if route.Method == fiber.MethodHead || undocumentedPaths[route.Path] {
+				continue
+			}
+			path := routeParamRegexp.ReplaceAllString(route.Path, "{$1}")
+			routes[strings.ToLower(route.Method)+" "+path] = true
+		}
+	}
+	return routes
+}
+
+func TestOpenAPISpecCoversRoutes(t *testing.T) {
+	server := newTestServer(t, nil)
+	spec := loadOpenAPISpec(t)
+
+	routes := registeredRoutes(server.router)
+	require.NotEmpty(t, routes)
+
+	for route := range routes {
+		fields := strings.Fields(route)
+		_, ok := spec.Paths[fields[1]][fields[0]]
+		require.True(t, ok, "route %q is missing from api/openapi.json", route)
+	}
+
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			route := method + " " + path
+			require.True(t, routes[route], "api/openapi.json documents %q, which is not a registered route", route)
+		}
+	}
+}
+
+func TestDocsAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		url           string
+		checkResponse func(t *testing.T, response *http.Response)
+	}{
+		{
+			name: "OpenAPISpec",
+			url:  "/openapi.json",
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+				require.Equal(t, fiber.MIMEApplicationJSON, response.Header.Get(fiber.HeaderContentType))
+
+				data, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+				require.JSONEq(t, string(openAPISpec), string(data))
+			},
+		},
+		{
+			name: "SwaggerUI",
+			url:  "/docs",
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+
+				data, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+				require.Contains(t, string(data), `url: "/openapi.json"`)
+			},
+		},
+		{
+			name: "SwaggerUIAssets",
+			url:  "/docs/swagger-ui-bundle.js",
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+
+			request := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			response, err := server.router.Test(request, -1)
+			require.NoError(t, err)
+			tc.checkResponse(t, response)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	db "simple_bank/db/sqlc"
+	"simple_bank/token"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type accountEntryRequest struct {
+	AccountID int64  `params:"id" validate:"required,min=1"`
+	Amount    int64  `json:"amount" validate:"required,gt=0"`
+	Currency  string `json:"currency" validate:"required,currency"`
+}
+
+type entryTxFunc func(ctx context.Context, arg db.EntryTxParams) (db.EntryTxResult, error)
+
+func (server *Server) createDeposit(ctx *fiber.Ctx) error {
+	return server.createAccountEntry(ctx, server.store.DepositTx)
+}
+
+func (server *Server) createWithdrawal(ctx *fiber.Ctx) error {
+	return server.createAccountEntry(ctx, server.store.WithdrawTx)
+}
+
+func (server *Server) createAccountEntry(ctx *fiber.Ctx, entryTx entryTxFunc) error {
+	req := new(accountEntryRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	account, err := server.store.GetAccount(ctx.Context(), req.AccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errAccountNotFound
+		}
+		return err
+	}
+
+	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
+
+	if authPayload.Username != account.Owner {
+		return errAccountNotOwned
+	}
+
+	if account.Currency != req.Currency {
+		return currencyMismatch(account, req.Currency)
+	}
+
+	arg := db.EntryTxParams{
+		AccountID: account.ID,
+		Amount:    req.Amount,
+	}
+
+	result, err := entryTx(ctx.Context(), arg)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(result)
+}
+
+type balanceCorrectionRequest struct {
+	AccountID int64  `params:"id" validate:"required,min=1"`
+	Amount    int64  `json:"amount" validate:"required,ne=0"`
+	Currency  string `json:"currency" validate:"required,currency"`
+}
+
+// createBalanceCorrection lets tellers and admins adjust any account's balance. The signed amount
+// is booked as a ledger entry so corrections stay auditable, unlike overwriting the balance.
+func (server *Server) createBalanceCorrection(ctx *fiber.Ctx) error {
+	req := new(balanceCorrectionRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	account, err := server.store.GetAccount(ctx.Context(), req.AccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errAccountNotFound
+		}
+		return err
+	}
+
+	if account.Currency != req.Currency {
+		return currencyMismatch(account, req.Currency)
+	}
+
+	arg := db.EntryTxParams{
+		AccountID: account.ID,
+		Amount:    req.Amount,
+	}
+
+	result, err := server.store.CorrectBalanceTx(ctx.Context(), arg)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(result)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"simple_bank/apierror"
+	db "simple_bank/db/sqlc"
+	"simple_bank/export"
+	"simple_bank/fx"
+	"simple_bank/revocation"
+	"simple_bank/schedule"
+	"simple_bank/statement"
+	"simple_bank/token"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	errUserNotFound    = apierror.New(fiber.StatusNotFound, apierror.CodeUserNotFound, "user not found")
+	errAccountNotFound = apierror.New(fiber.StatusNotFound, apierror.CodeAccountNotFound, "account not found")
+	errAccountNotOwned = apierror.New(fiber.StatusUnauthorized, apierror.CodeAccountNotOwned, "account doesn't belong to the authenticated user")
+
+	errWebhookNotFound         = apierror.New(fiber.StatusNotFound, apierror.CodeWebhookNotFound, "webhook not found")
+	errWebhookDeliveryNotFound = apierror.New(fiber.StatusNotFound, apierror.CodeWebhookDeliveryNotFound, "webhook delivery not found")
+)
+
+func currencyMismatch(account db.Account, currency string) *apierror.Error {
+	return apierror.Newf(fiber.StatusBadRequest, apierror.CodeCurrencyMismatch,
+		"account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, currency)
+}
+
+// domainErrors maps the errors of the store and services to API errors. Their messages are ours,
+// so unlike driver errors they are passed on to the client.
+var domainErrors = []struct {
+	err    error
+	status int
+	code   apierror.Code
+}{
+	{db.ErrInsufficientFunds, fiber.StatusUnprocessableEntity, apierror.CodeInsufficientFunds},
+	{db.ErrTransferLimitExceeded, fiber.StatusTooManyRequests, apierror.CodeTransferLimitExceeded},
+	{db.ErrIdempotencyKeyMismatch, fiber.StatusConflict, apierror.CodeIdempotencyKeyMismatch},
+	{db.ErrIdempotencyKeyConflict, fiber.StatusConflict, apierror.CodeIdempotencyKeyConflict},
+	{db.ErrFxQuoteUnavailable, fiber.StatusConflict, apierror.CodeFxQuoteUnavailable},
+	{db.ErrTransferNotReversible, fiber.StatusConflict, apierror.CodeTransferNotReversible},
+	{db.ErrTransferAlreadyReversed, fiber.StatusConflict, apierror.CodeTransferAlreadyReversed},
+	{db.ErrInvalidReversalAmount, fiber.StatusUnprocessableEntity, apierror.CodeInvalidReversalAmount},
+	{db.ErrHoldNotActive, fiber.StatusConflict, apierror.CodeHoldNotActive},
+	{db.ErrInvalidCaptureAmount, fiber.StatusUnprocessableEntity, apierror.CodeInvalidCaptureAmount},
+	{errScheduledTransferNotActive, fiber.StatusConflict, apierror.CodeScheduledTransferInactive},
+	{errQuoteNotOwned, fiber.StatusUnauthorized, apierror.CodeResourceNotOwned},
+	{errQuoteMismatch, fiber.StatusBadRequest, apierror.CodeFxQuoteMismatch},
+	{errInvalidCursor, fiber.StatusBadRequest, apierror.CodeValidationFailed},
+	{fx.ErrRateNotFound, fiber.StatusBadRequest, apierror.CodeFxRateNotFound},
+	{fx.ErrAmountTooSmall, fiber.StatusUnprocessableEntity, apierror.CodeFxAmountTooSmall},
+	{schedule.ErrInvalidRule, fiber.StatusBadRequest, apierror.CodeInvalidSchedule},
+	{statement.ErrInvalidPeriod, fiber.StatusBadRequest, apierror.CodeInvalidPeriod},
+	{export.ErrUnsupportedFormat, fiber.StatusNotAcceptable, apierror.CodeNotAcceptable},
+	{token.ErrInvalidToken, fiber.StatusUnauthorized, apierror.CodeInvalidToken},
+	{token.ErrExpiredToken, fiber.StatusUnauthorized, apierror.CodeInvalidToken},
+	{token.ErrWrongType, fiber.StatusUnauthorized, apierror.CodeInvalidToken},
+	{revocation.ErrRevokedToken, fiber.StatusUnauthorized, apierror.CodeTokenRevoked},
+}
+
+func toAPIError(err error) *apierror.Error {
+	var apiErr *apierror.Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	for _, domainErr := range domainErrors {
+		if errors.Is(err, domainErr.err) {
+			return apierror.Wrap(domainErr.status, domainErr.code, err.Error(), err)
+		}
+	}
+
+	return apierror.From(err)
+}
+
+// errorHandler writes every error returned by a handler or middleware as an apierror.Error, tagged
+// with the ID of the request
+func errorHandler(ctx *fiber.Ctx, err error) error {
+	apiErr := *toAPIError(err)
+	apiErr.RequestID = ctx.GetRespHeader(fiber.HeaderXRequestID)
+
+	if apiErr.Status >= fiber.StatusInternalServerError {
+		log.Printf("request %s failed: %v", apiErr.RequestID, err)
+	}
+
+	return ctx.Status(apiErr.Status).JSON(apiErr)
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"simple_bank/apierror"
+	mockdb "simple_bank/db/mock"
+	"simple_bank/token"
+	"simple_bank/util"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorResponse(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		body          fiber.Map
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, response *http.Response, apiErr apierror.Error)
+	}{
+		{
+			name:   "ValidationFailed",
+			method: http.MethodPost,
+			url:    "/accounts",
+			body: fiber.Map{
+				"currency": "invalid",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response, apiErr apierror.Error) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+				require.Equal(t, apierror.CodeValidationFailed, apiErr.Code)
+				require.Len(t, apiErr.Details, 1)
+				require.Equal(t, "currency", apiErr.Details[0].Field)
+				require.Equal(t, "must be a supported currency", apiErr.Details[0].Reason)
+			},
+		},
+		{
+			name:   "AccountNotFound",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/account/%d", account.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, response *http.Response, apiErr apierror.Error) {
+				require.Equal(t, http.StatusNotFound, response.StatusCode)
+				require.Equal(t, apierror.CodeAccountNotFound, apiErr.Code)
+				require.Empty(t, apiErr.Details)
+			},
+		},
+		{
+			name:   "InternalError",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/account/%d", account.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, response *http.Response, apiErr apierror.Error) {
+				require.Equal(t, http.StatusInternalServerError, response.StatusCode)
+				require.Equal(t, apierror.CodeInternal, apiErr.Code)
+				require.NotContains(t, apiErr.Message, sql.ErrConnDone.Error())
+			},
+		},
+		{
+			name:   "NoAuthorization",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/account/%d", account.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response, apiErr apierror.Error) {
+				require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+				require.Equal(t, apierror.CodeUnauthorized, apiErr.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			var body io.Reader
+			if tc.body != nil {
+				data, err := json.Marshal(tc.body)
+				require.NoError(t, err)
+				body = bytes.NewReader(data)
+			}
+
+			request := httptest.NewRequest(tc.method, tc.url, body)
+			request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			tc.setupAuth(t, request, server.tokenMaker)
+
+			response, err := server.router.Test(request)
+			require.NoError(t, err)
+
+			apiErr := requireBodyAPIError(t, response.Body)
+			require.NotEmpty(t, apiErr.Message)
+			require.NotEmpty(t, apiErr.RequestID)
+			require.Equal(t, response.Header.Get(fiber.HeaderXRequestID), apiErr.RequestID)
+			tc.checkResponse(t, response, apiErr)
+		})
+	}
+}
+
+func requireBodyAPIError(t *testing.T, body io.Reader) apierror.Error {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var apiErr apierror.Error
+	err = json.Unmarshal(data, &apiErr)
+	require.NoError(t, err)
+	return apiErr
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	db "simple_bank/db/sqlc"
+	"simple_bank/fx"
+	"simple_bank/token"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+var (
+	errQuoteNotOwned = errors.New("fx quote doesn't belong to the authenticated user")
+	errQuoteMismatch = errors.New("fx quote doesn't match the transfer currencies and amount")
+)
+
+type createFxQuoteRequest struct {
+	FromCurrency string `json:"from_currency" validate:"required,currency"`
+	ToCurrency   string `json:"to_currency" validate:"required,currency,nefield=FromCurrency"`
+	Amount       int64  `json:"amount" validate:"required,gt=0"`
+}
+
+// createFxQuote locks the current rate for a conversion so the user can review it
+// before committing to a transfer that references the quote
+func (server *Server) createFxQuote(ctx *fiber.Ctx) error {
+	req := new(createFxQuoteRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	exchange, err := fx.NewTransferExchange(ctx.Context(), server.rateProvider, req.FromCurrency, req.ToCurrency, req.Amount)
+	if err != nil {
+		return err
+	}
+
+	quoteID, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+
+	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
+
+	quote, err := server.store.CreateFxQuote(ctx.Context(), db.CreateFxQuoteParams{
+		ID:           quoteID,
+		Username:     authPayload.Username,
+		FromCurrency: exchange.FromCurrency,
+		ToCurrency:   exchange.ToCurrency,
+		Rate:         exchange.Rate,
+		FromAmount:   req.Amount,
+		ToAmount:     exchange.ToAmount,
+		ExpiresAt:    time.Now().Add(server.config.FXQuoteDuration),
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(quote)
+}
+
+// quotedTransferExchange checks that the quote matches the transfer and returns its locked conversion.
+// Whether the quote is still available is left to the caller, see quoteAvailable.
+func quotedTransferExchange(quote db.FxQuote, username string, from db.Account, to db.Account, amount int64) (*db.TransferExchange, error) {
+	if quote.Username != username {
+		return nil, errQuoteNotOwned
+	}
+
+	if quote.FromCurrency != from.Currency || quote.ToCurrency != to.Currency || quote.FromAmount != amount {
+		return nil, errQuoteMismatch
+	}
+
+	return &db.TransferExchange{
+		FromCurrency: quote.FromCurrency,
+		ToCurrency:   quote.ToCurrency,
+		Rate:         quote.Rate,
+		ToAmount:     quote.ToAmount,
+		QuoteID:      &quote.ID,
+	}, nil
+}
+
+// quoteAvailable reports whether the quote can still be used. The transfer transaction checks it again
+// when it consumes the quote.
+func quoteAvailable(quote db.FxQuote) bool {
+	return quote.UsedAt == nil && time.Now().Before(quote.ExpiresAt)
+}
@@ -0,0 +1,475 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"simple_bank/apierror"
+	mockdb "simple_bank/db/mock"
+	db "simple_bank/db/sqlc"
+	"simple_bank/token"
+	"simple_bank/util"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateFxQuoteAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          fiber.Map
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, response *http.Response)
+	}{
+		{
+			name: "OK",
+			body: fiber.Map{
+				"from_currency": "USD",
+				"to_currency":   "EUR",
+				"amount":        100,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateFxQuote(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateFxQuoteParams) (db.FxQuote, error) {
+						require.NotZero(t, arg.ID)
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, "0.9", arg.Rate)
+						require.Equal(t, int64(100), arg.FromAmount)
+						require.Equal(t, int64(90), arg.ToAmount)
+						require.True(t, arg.ExpiresAt.After(time.Now()))
+						return db.FxQuote{
+							ID:           arg.ID,
+							Username:     arg.Username,
+							FromCurrency: arg.FromCurrency,
+							ToCurrency:   arg.ToCurrency,
+							Rate:         arg.Rate,
+							FromAmount:   arg.FromAmount,
+							ToAmount:     arg.ToAmount,
+							ExpiresAt:    arg.ExpiresAt,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+
+				data, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+
+				var quote db.FxQuote
+				err = json.Unmarshal(data, &quote)
+				require.NoError(t, err)
+				require.NotZero(t, quote.ID)
+				require.Equal(t, "USD", quote.FromCurrency)
+				require.Equal(t, "EUR", quote.ToCurrency)
+				require.Equal(t, int64(90), quote.ToAmount)
+				require.Nil(t, quote.UsedAt)
+			},
+		},
+		{
+			name: "SameCurrency",
+			body: fiber.Map{
+				"from_currency": "USD",
+				"to_currency":   "USD",
+				"amount":        100,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name: "RateNotFound",
+			body: fiber.Map{
+				"from_currency": "USD",
+				"to_currency":   "KRW",
+				"amount":        100,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name: "AmountTooSmall",
+			// 5 won are worth less than half a cent
+			body: fiber.Map{
+				"from_currency": "KRW",
+				"to_currency":   "USD",
+				"amount":        5,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+				require.Equal(t, apierror.CodeFxAmountTooSmall, requireBodyAPIError(t, response.Body).Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: fiber.Map{
+				"from_currency": "USD",
+				"to_currency":   "EUR",
+				"amount":        100,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			name: "InternalError",
+			body: fiber.Map{
+				"from_currency": "USD",
+				"to_currency":   "EUR",
+				"amount":        100,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateFxQuote(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FxQuote{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, response.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request := httptest.NewRequest(http.MethodPost, "/fx/quotes", bytes.NewReader(data))
+			request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			tc.setupAuth(t, request, server.tokenMaker)
+
+			response, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, response)
+		})
+	}
+}
+
+func TestTransferAPIWithFxQuote(t *testing.T) {
+	amount := int64(100)
+
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account3 := randomAccount(user2.Username)
+	account2.ID = account1.ID + 1
+	account3.ID = account1.ID + 2
+	account1.Currency = "USD"
+	account2.Currency = "EUR"
+	account3.Currency = "USD"
+
+	// the locked rate deliberately differs from the live one
+	quote := db.FxQuote{
+		ID:           uuid.New(),
+		Username:     user1.Username,
+		FromCurrency: "USD",
+		ToCurrency:   "EUR",
+		Rate:         "0.85",
+		FromAmount:   amount,
+		ToAmount:     85,
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+	usedQuote := quote
+	usedAt := time.Now()
+	usedQuote.UsedAt = &usedAt
+
+	// the result stored by the first attempt of a retried transfer
+	result := db.TransferTxResult{
+		Transfer: db.Transfer{
+			ID:            util.RandomInt(1, 1000),
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		toAccount      db.Account
+		idempotencyKey string
+		buildQuote     func() db.FxQuote
+		buildStubs     func(store *mockdb.MockStore, quote db.FxQuote)
+		checkResponse  func(t *testing.T, response *http.Response)
+	}{
+		{
+			name:      "OK",
+			toAccount: account2,
+			buildQuote: func() db.FxQuote {
+				return quote
+			},
+			buildStubs: func(store *mockdb.MockStore, quote db.FxQuote) {
+				arg := db.CrossCurrencyTransferTxParams{
+					TransferTxParams: db.TransferTxParams{
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        amount,
+					},
+					Exchange: db.TransferExchange{
+						FromCurrency: "USD",
+						ToCurrency:   "EUR",
+						Rate:         "0.85",
+						ToAmount:     85,
+						QuoteID:      &quote.ID,
+					},
+				}
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Eq(quote.ID)).Times(1).Return(quote, nil)
+				store.EXPECT().CrossCurrencyTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+			},
+		},
+		{
+			name:      "QuoteNotFound",
+			toAccount: account2,
+			buildQuote: func() db.FxQuote {
+				return quote
+			},
+			buildStubs: func(store *mockdb.MockStore, quote db.FxQuote) {
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Any()).Times(1).Return(db.FxQuote{}, sql.ErrNoRows)
+				store.EXPECT().CrossCurrencyTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusNotFound, response.StatusCode)
+			},
+		},
+		{
+			name:      "QuoteOfOtherUser",
+			toAccount: account2,
+			buildQuote: func() db.FxQuote {
+				other := quote
+				other.Username = user2.Username
+				return other
+			},
+			buildStubs: func(store *mockdb.MockStore, quote db.FxQuote) {
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Any()).Times(1).Return(quote, nil)
+				store.EXPECT().CrossCurrencyTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			name:      "QuoteExpired",
+			toAccount: account2,
+			buildQuote: func() db.FxQuote {
+				expired := quote
+				expired.ExpiresAt = time.Now().Add(-time.Second)
+				return expired
+			},
+			buildStubs: func(store *mockdb.MockStore, quote db.FxQuote) {
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Any()).Times(1).Return(quote, nil)
+				store.EXPECT().CrossCurrencyTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusConflict, response.StatusCode)
+			},
+		},
+		{
+			name:      "QuoteAlreadyUsed",
+			toAccount: account2,
+			buildQuote: func() db.FxQuote {
+				used := quote
+				usedAt := time.Now()
+				used.UsedAt = &usedAt
+				return used
+			},
+			buildStubs: func(store *mockdb.MockStore, quote db.FxQuote) {
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Any()).Times(1).Return(quote, nil)
+				store.EXPECT().CrossCurrencyTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusConflict, response.StatusCode)
+			},
+		},
+		{
+			name:           "ReplayWithUsedQuote",
+			toAccount:      account2,
+			idempotencyKey: "transfer-1",
+			buildQuote: func() db.FxQuote {
+				return usedQuote
+			},
+			buildStubs: func(store *mockdb.MockStore, quote db.FxQuote) {
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Eq(quote.ID)).Times(1).Return(quote, nil)
+				store.EXPECT().CrossCurrencyTransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					IdempotentTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.IdempotentTransferTxParams) (db.TransferTxResult, error) {
+						require.Equal(t, "transfer-1", arg.Key)
+						require.NotNil(t, arg.Exchange)
+						require.Equal(t, &quote.ID, arg.Exchange.QuoteID)
+						return result, nil
+					})
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+
+				data, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+
+				var gotResult db.TransferTxResult
+				err = json.Unmarshal(data, &gotResult)
+				require.NoError(t, err)
+				require.Equal(t, result, gotResult)
+			},
+		},
+		{
+			name:           "UsedQuoteWithNewIdempotencyKey",
+			toAccount:      account2,
+			idempotencyKey: "transfer-2",
+			buildQuote: func() db.FxQuote {
+				return usedQuote
+			},
+			buildStubs: func(store *mockdb.MockStore, quote db.FxQuote) {
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Any()).Times(1).Return(quote, nil)
+				store.EXPECT().
+					IdempotentTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrFxQuoteUnavailable)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusConflict, response.StatusCode)
+			},
+		},
+		{
+			name:      "QuoteUsedConcurrently",
+			toAccount: account2,
+			buildQuote: func() db.FxQuote {
+				return quote
+			},
+			buildStubs: func(store *mockdb.MockStore, quote db.FxQuote) {
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Any()).Times(1).Return(quote, nil)
+				store.EXPECT().
+					CrossCurrencyTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrFxQuoteUnavailable)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusConflict, response.StatusCode)
+			},
+		},
+		{
+			name:      "QuoteAmountMismatch",
+			toAccount: account2,
+			buildQuote: func() db.FxQuote {
+				mismatch := quote
+				mismatch.FromAmount = amount + 1
+				return mismatch
+			},
+			buildStubs: func(store *mockdb.MockStore, quote db.FxQuote) {
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Any()).Times(1).Return(quote, nil)
+				store.EXPECT().CrossCurrencyTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name:      "SameCurrency",
+			toAccount: account3,
+			buildQuote: func() db.FxQuote {
+				return quote
+			},
+			buildStubs: func(store *mockdb.MockStore, quote db.FxQuote) {
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			quote := tc.buildQuote()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(tc.toAccount.ID)).Times(1).Return(tc.toAccount, nil)
+			tc.buildStubs(store, quote)
+
+			server := newTestServer(t, store)
+
+			data, err := json.Marshal(fiber.Map{
+				"from_account_id": account1.ID,
+				"to_account_id":   tc.toAccount.ID,
+				"amount":          amount,
+				"currency":        "USD",
+				"quote_id":        quote.ID,
+			})
+			require.NoError(t, err)
+
+			request := httptest.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+			request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, util.CustomerRole, time.Minute)
+			if len(tc.idempotencyKey) > 0 {
+				request.Header.Set(idempotencyKeyHeader, tc.idempotencyKey)
+			}
+
+			response, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, response)
+		})
+	}
+}
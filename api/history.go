@@ -0,0 +1,214 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"math"
+	db "simple_bank/db/sqlc"
+	"simple_bank/token"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultHistoryPageSize = 20
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+type listHistoryRequest struct {
+	AccountID int64  `params:"id" validate:"required,min=1"`
+	From      string `query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To        string `query:"to" validate:"omitempty,datetime=2006-01-02"`
+	MinAmount int64  `query:"min_amount" validate:"gte=0"`
+	MaxAmount int64  `query:"max_amount" validate:"omitempty,gtefield=MinAmount"`
+	Direction string `query:"direction" validate:"omitempty,oneof=in out"`
+	PageSize  int32  `query:"page_size" validate:"omitempty,min=1,max=100"`
+	Cursor    string `query:"cursor"`
+}
+
+// historyFilter holds the bounds of a history page, with unset filters widened to match everything
+type historyFilter struct {
+	BeforeID  int64
+	FromTime  time.Time
+	ToTime    time.Time
+	MinAmount int64
+	MaxAmount int64
+	Direction string
+	PageSize  int32
+}
+
+func (req *listHistoryRequest) filter() (historyFilter, error) {
+	filter := historyFilter{
+		BeforeID:  math.MaxInt64,
+		ToTime:    time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC),
+		MinAmount: req.MinAmount,
+		MaxAmount: math.MaxInt64,
+		Direction: req.Direction,
+		PageSize:  defaultHistoryPageSize,
+	}
+
+	if len(req.Cursor) > 0 {
+		beforeID, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return historyFilter{}, err
+		}
+		filter.BeforeID = beforeID
+	}
+	if len(req.From) > 0 {
+		filter.FromTime, _ = time.Parse(statementDateLayout, req.From)
+	}
+	if len(req.To) > 0 {
+		to, _ := time.Parse(statementDateLayout, req.To)
+		filter.ToTime = to.AddDate(0, 0, 1)
+	}
+	if req.MaxAmount > 0 {
+		filter.MaxAmount = req.MaxAmount
+	}
+	if req.PageSize > 0 {
+		filter.PageSize = req.PageSize
+	}
+
+	return filter, nil
+}
+
+// encodeCursor returns an opaque cursor pointing after the row with the given id
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func decodeCursor(cursor string) (int64, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errInvalidCursor
+	}
+
+	id, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil || id < 1 {
+		return 0, errInvalidCursor
+	}
+	return id, nil
+}
+
+// parseHistoryRequest validates the request and checks the authenticated user may read the account's history
+func (server *Server) parseHistoryRequest(ctx *fiber.Ctx) (db.Account, historyFilter, error) {
+	req := new(listHistoryRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return db.Account{}, historyFilter{}, err
+	}
+
+	filter, err := req.filter()
+	if err != nil {
+		return db.Account{}, historyFilter{}, err
+	}
+
+	account, err := server.store.GetAccount(ctx.Context(), req.AccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.Account{}, historyFilter{}, errAccountNotFound
+		}
+		return db.Account{}, historyFilter{}, err
+	}
+
+	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
+
+	if authPayload.Username != account.Owner && !isStaff(authPayload) {
+		return db.Account{}, historyFilter{}, errAccountNotOwned
+	}
+
+	return account, filter, nil
+}
+
+type entryResponse struct {
+	db.Entry
+	Formatted formattedAmounts `json:"formatted,omitempty"`
+}
+
+type listAccountEntriesResponse struct {
+	Entries    []entryResponse `json:"entries"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+func (server *Server) listAccountEntries(ctx *fiber.Ctx) error {
+	account, filter, err := server.parseHistoryRequest(ctx)
+	if err != nil {
+		return err
+	}
+
+	// fetch one extra row to find out whether there is a next page
+	arg := db.ListAccountEntriesParams{
+		AccountID: account.ID,
+		BeforeID:  filter.BeforeID,
+		FromTime:  filter.FromTime,
+		ToTime:    filter.ToTime,
+		MinAmount: filter.MinAmount,
+		MaxAmount: filter.MaxAmount,
+		Direction: filter.Direction,
+		PageSize:  filter.PageSize + 1,
+	}
+
+	entries, err := server.store.ListAccountEntries(ctx.Context(), arg)
+	if err != nil {
+		return err
+	}
+
+	var rsp listAccountEntriesResponse
+	if len(entries) > int(filter.PageSize) {
+		entries = entries[:filter.PageSize]
+		rsp.NextCursor = encodeCursor(entries[filter.PageSize-1].ID)
+	}
+
+	// entries are always in the currency of their account
+	formatted := wantsFormattedAmounts(ctx)
+	rsp.Entries = make([]entryResponse, 0, len(entries))
+	for _, entry := range entries {
+		item := entryResponse{Entry: entry}
+		if formatted {
+			item.Formatted, err = formatAmounts(account.Currency, map[string]int64{"amount": entry.Amount})
+			if err != nil {
+				return err
+			}
+		}
+		rsp.Entries = append(rsp.Entries, item)
+	}
+
+	return ctx.JSON(rsp)
+}
+
+type listAccountTransfersResponse struct {
+	Transfers  []db.Transfer `json:"transfers"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+func (server *Server) listAccountTransfers(ctx *fiber.Ctx) error {
+	account, filter, err := server.parseHistoryRequest(ctx)
+	if err != nil {
+		return err
+	}
+
+	arg := db.ListAccountTransfersParams{
+		AccountID: account.ID,
+		BeforeID:  filter.BeforeID,
+		FromTime:  filter.FromTime,
+		ToTime:    filter.ToTime,
+		MinAmount: filter.MinAmount,
+		MaxAmount: filter.MaxAmount,
+		Direction: filter.Direction,
+		PageSize:  filter.PageSize + 1,
+	}
+
+	transfers, err := server.store.ListAccountTransfers(ctx.Context(), arg)
+	if err != nil {
+		return err
+	}
+
+	rsp := listAccountTransfersResponse{Transfers: transfers}
+	if len(transfers) > int(filter.PageSize) {
+		rsp.Transfers = transfers[:filter.PageSize]
+		rsp.NextCursor = encodeCursor(rsp.Transfers[filter.PageSize-1].ID)
+	}
+
+	return ctx.JSON(rsp)
+}
@@ -0,0 +1,378 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple_bank/db/mock"
+	db "simple_bank/db/sqlc"
+	"simple_bank/token"
+	"simple_bank/util"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAccountEntriesAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	entries := make([]db.Entry, 3)
+	for i := range entries {
+		entries[i] = db.Entry{
+			ID:        int64(30 - i),
+			AccountID: account.ID,
+			Amount:    util.RandomMoney(),
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, response *http.Response)
+	}{
+		{
+			name:  "OK",
+			query: "page_size=2",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountEntriesParams{
+					AccountID: account.ID,
+					BeforeID:  math.MaxInt64,
+					ToTime:    time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC),
+					MaxAmount: math.MaxInt64,
+					PageSize:  3,
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountEntries(gomock.Any(), gomock.Eq(arg)).Times(1).Return(entries, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+
+				rsp := decodeEntriesResponse(t, response)
+				require.Equal(t, newEntryResponses(entries[:2]), rsp.Entries)
+				require.Equal(t, encodeCursor(entries[1].ID), rsp.NextCursor)
+			},
+		},
+		{
+			name:  "Filters",
+			query: fmt.Sprintf("from=2022-06-01&to=2022-06-30&min_amount=5&max_amount=50&direction=out&cursor=%s", encodeCursor(100)),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountEntriesParams{
+					AccountID: account.ID,
+					BeforeID:  100,
+					FromTime:  time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+					ToTime:    time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC),
+					MinAmount: 5,
+					MaxAmount: 50,
+					Direction: "out",
+					PageSize:  defaultHistoryPageSize + 1,
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountEntries(gomock.Any(), gomock.Eq(arg)).Times(1).Return(entries, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+
+				rsp := decodeEntriesResponse(t, response)
+				require.Equal(t, newEntryResponses(entries), rsp.Entries)
+				require.Empty(t, rsp.NextCursor)
+			},
+		},
+		{
+			name:  "FormattedAmounts",
+			query: "formatted_amounts=true",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				usdAccount := account
+				usdAccount.Currency = "USD"
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(usdAccount, nil)
+				store.EXPECT().ListAccountEntries(gomock.Any(), gomock.Any()).Times(1).Return([]db.Entry{
+					{ID: 2, AccountID: account.ID, Amount: -123450},
+					{ID: 1, AccountID: account.ID, Amount: 5},
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+
+				rsp := decodeEntriesResponse(t, response)
+				require.Len(t, rsp.Entries, 2)
+				require.Equal(t, formattedAmounts{"amount": "-$1,234.50"}, rsp.Entries[0].Formatted)
+				require.Equal(t, formattedAmounts{"amount": "$0.05"}, rsp.Entries[1].Formatted)
+			},
+		},
+		{
+			name: "Teller",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "teller", util.TellerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountEntries(gomock.Any(), gomock.Any()).Times(1).Return([]db.Entry{}, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			name:  "InvalidCursor",
+			query: "cursor=not-a-cursor",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name:  "InvalidDirection",
+			query: "direction=sideways",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name:  "MaxAmountBelowMinAmount",
+			query: "min_amount=50&max_amount=10",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name:  "InvalidPageSize",
+			query: "page_size=1000",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name: "AccountNotFound",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusNotFound, response.StatusCode)
+			},
+		},
+		{
+			name: "InternalError",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountEntries(gomock.Any(), gomock.Any()).Times(1).Return([]db.Entry{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, response.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			url := fmt.Sprintf("/accounts/%d/entries?%s", account.ID, tc.query)
+			request := httptest.NewRequest(http.MethodGet, url, nil)
+			tc.setupAuth(t, request, server.tokenMaker)
+
+			response, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, response)
+		})
+	}
+}
+
+func TestListAccountTransfersAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	transfers := []db.Transfer{
+		{ID: 12, FromAccountID: account.ID, ToAccountID: account.ID + 1, Amount: 10},
+		{ID: 11, FromAccountID: account.ID + 1, ToAccountID: account.ID, Amount: 20},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, response *http.Response)
+	}{
+		{
+			name:  "OK",
+			query: "page_size=1&direction=in",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountTransfersParams{
+					AccountID: account.ID,
+					BeforeID:  math.MaxInt64,
+					ToTime:    time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC),
+					MaxAmount: math.MaxInt64,
+					Direction: "in",
+					PageSize:  2,
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+
+				data, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+
+				var rsp listAccountTransfersResponse
+				err = json.Unmarshal(data, &rsp)
+				require.NoError(t, err)
+				require.Len(t, rsp.Transfers, 1)
+				require.Equal(t, transfers[0].ID, rsp.Transfers[0].ID)
+				require.Equal(t, encodeCursor(transfers[0].ID), rsp.NextCursor)
+			},
+		},
+		{
+			name:  "InvalidDate",
+			query: "from=yesterday",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Any()).Times(1).Return([]db.Transfer{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, response.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			url := fmt.Sprintf("/accounts/%d/transfers?%s", account.ID, tc.query)
+			request := httptest.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+
+			response, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, response)
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	id, err := decodeCursor(encodeCursor(42))
+	require.NoError(t, err)
+	require.Equal(t, int64(42), id)
+
+	for _, cursor := range []string{"%%%", encodeCursor(0), "YWJj"} {
+		_, err = decodeCursor(cursor)
+		require.ErrorIs(t, err, errInvalidCursor)
+	}
+}
+
+func newEntryResponses(entries []db.Entry) []entryResponse {
+	rsp := make([]entryResponse, 0, len(entries))
+	for _, entry := range entries {
+		rsp = append(rsp, entryResponse{Entry: entry})
+	}
+	return rsp
+}
+
+func decodeEntriesResponse(t *testing.T, response *http.Response) listAccountEntriesResponse {
+	data, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	var rsp listAccountEntriesResponse
+	err = json.Unmarshal(data, &rsp)
+	require.NoError(t, err)
+	return rsp
+}
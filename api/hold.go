@@ -0,0 +1,190 @@
+package api
+
+import (
+	"database/sql"
+	"simple_bank/apierror"
+	db "simple_bank/db/sqlc"
+	"simple_bank/token"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type placeHoldRequest struct {
+	AccountID int64  `params:"id" validate:"required,min=1"`
+	Amount    int64  `json:"amount" validate:"required,gt=0"`
+	Currency  string `json:"currency" validate:"required,currency"`
+	// ExpiresAt defaults to the configured hold duration from now
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (server *Server) placeHold(ctx *fiber.Ctx) error {
+	req := new(placeHoldRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(server.config.HoldDuration)
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			apiErr := apierror.New(fiber.StatusBadRequest, apierror.CodeValidationFailed, "request validation failed")
+			apiErr.Details = []apierror.FieldError{{Field: "expires_at", Reason: "must be in the future"}}
+			return apiErr
+		}
+		expiresAt = *req.ExpiresAt
+	}
+
+	account, err := server.store.GetAccount(ctx.Context(), req.AccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errAccountNotFound
+		}
+		return err
+	}
+
+	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
+
+	if authPayload.Username != account.Owner && !isStaff(authPayload) {
+		return errAccountNotOwned
+	}
+
+	if account.Currency != req.Currency {
+		return currencyMismatch(account, req.Currency)
+	}
+
+	hold, err := server.store.PlaceHoldTx(ctx.Context(), db.PlaceHoldTxParams{
+		AccountID: account.ID,
+		Amount:    req.Amount,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(hold)
+}
+
+type listActiveHoldsRequest struct {
+	AccountID int64 `params:"id" validate:"required,min=1"`
+}
+
+func (server *Server) listActiveHolds(ctx *fiber.Ctx) error {
+	req := new(listActiveHoldsRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	account, err := server.store.GetAccount(ctx.Context(), req.AccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errAccountNotFound
+		}
+		return err
+	}
+
+	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
+
+	if authPayload.Username != account.Owner && !isStaff(authPayload) {
+		return errAccountNotOwned
+	}
+
+	holds, err := server.store.ListActiveHolds(ctx.Context(), account.ID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(holds)
+}
+
+// authorizedHold loads the hold and checks the authenticated user may act on its account
+func (server *Server) authorizedHold(ctx *fiber.Ctx, holdID int64) (db.Hold, db.Account, error) {
+	hold, err := server.store.GetHold(ctx.Context(), holdID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.Hold{}, db.Account{}, apierror.New(fiber.StatusNotFound, apierror.CodeHoldNotFound, "hold not found")
+		}
+		return db.Hold{}, db.Account{}, err
+	}
+
+	account, err := server.store.GetAccount(ctx.Context(), hold.AccountID)
+	if err != nil {
+		return db.Hold{}, db.Account{}, err
+	}
+
+	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
+
+	if authPayload.Username != account.Owner && !isStaff(authPayload) {
+		return db.Hold{}, db.Account{}, errAccountNotOwned
+	}
+
+	return hold, account, nil
+}
+
+type captureHoldRequest struct {
+	HoldID      int64 `params:"id" validate:"required,min=1"`
+	ToAccountID int64 `json:"to_account_id" validate:"required,min=1"`
+	// Amount defaults to the whole hold
+	Amount int64 `json:"amount" validate:"omitempty,gt=0"`
+}
+
+func (server *Server) captureHold(ctx *fiber.Ctx) error {
+	req := new(captureHoldRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	hold, account, err := server.authorizedHold(ctx, req.HoldID)
+	if err != nil {
+		return err
+	}
+
+	toAccount, err := server.store.GetAccount(ctx.Context(), req.ToAccountID)
+	if err != nil {
+		return apierror.Wrap(fiber.StatusBadRequest, apierror.CodeAccountNotFound, "invalid to_account", err)
+	}
+
+	if toAccount.Currency != account.Currency {
+		return currencyMismatch(toAccount, account.Currency)
+	}
+
+	result, err := server.store.CaptureHoldTx(ctx.Context(), db.CaptureHoldTxParams{
+		HoldID:      hold.ID,
+		ToAccountID: toAccount.ID,
+		Amount:      req.Amount,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(result)
+}
+
+type releaseHoldRequest struct {
+	HoldID int64 `params:"id" validate:"required,min=1"`
+}
+
+func (server *Server) releaseHold(ctx *fiber.Ctx) error {
+	req := new(releaseHoldRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	hold, _, err := server.authorizedHold(ctx, req.HoldID)
+	if err != nil {
+		return err
+	}
+
+	hold, err = server.store.ReleaseHold(ctx.Context(), hold.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.ErrHoldNotActive
+		}
+		return err
+	}
+
+	return ctx.JSON(hold)
+}
@@ -0,0 +1,488 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple_bank/db/mock"
+	db "simple_bank/db/sqlc"
+	"simple_bank/util"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceHoldAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	amount := int64(50)
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	hold := db.Hold{
+		ID:        util.RandomInt(1, 1000),
+		AccountID: account.ID,
+		Amount:    amount,
+		Status:    db.HoldStatusActive,
+		ExpiresAt: expiresAt,
+	}
+
+	testCases := []struct {
+		name          string
+		body          fiber.Map
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, response *http.Response)
+	}{
+		{
+			name:     "OK",
+			body:     fiber.Map{"amount": amount, "currency": account.Currency, "expires_at": expiresAt},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.PlaceHoldTxParams{
+					AccountID: account.ID,
+					Amount:    amount,
+					ExpiresAt: expiresAt,
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().PlaceHoldTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(hold, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+
+				data, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+
+				var gotHold db.Hold
+				err = json.Unmarshal(data, &gotHold)
+				require.NoError(t, err)
+				require.Equal(t, hold.ID, gotHold.ID)
+				require.Equal(t, db.HoldStatusActive, gotHold.Status)
+			},
+		},
+		{
+			name:     "DefaultExpiry",
+			body:     fiber.Map{"amount": amount, "currency": account.Currency},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					PlaceHoldTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.PlaceHoldTxParams) (db.Hold, error) {
+						require.WithinDuration(t, time.Now().Add(time.Minute), arg.ExpiresAt, time.Second)
+						return hold, nil
+					})
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+			},
+		},
+		{
+			name:     "ExpiresInThePast",
+			body:     fiber.Map{"amount": amount, "currency": account.Currency, "expires_at": time.Now().Add(-time.Hour)},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name:     "InvalidAmount",
+			body:     fiber.Map{"amount": 0, "currency": account.Currency},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			body:     fiber.Map{"amount": amount, "currency": account.Currency},
+			username: "unauthorized_user",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().PlaceHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			name:     "AccountNotFound",
+			body:     fiber.Map{"amount": amount, "currency": account.Currency},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusNotFound, response.StatusCode)
+			},
+		},
+		{
+			name:     "InsufficientFunds",
+			body:     fiber.Map{"amount": amount, "currency": account.Currency},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().PlaceHoldTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Hold{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+			},
+		},
+		{
+			name:     "PlaceHoldTxError",
+			body:     fiber.Map{"amount": amount, "currency": account.Currency},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().PlaceHoldTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Hold{}, sql.ErrTxDone)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, response.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/holds", account.ID)
+			request := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.CustomerRole, time.Minute)
+
+			response, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, response)
+		})
+	}
+}
+
+func TestCaptureHoldAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	toAccount := randomAccount(util.RandomOwner())
+	toAccount.ID = account.ID + 1
+	toAccount.Currency = account.Currency
+
+	hold := db.Hold{
+		ID:        util.RandomInt(1, 1000),
+		AccountID: account.ID,
+		Amount:    50,
+		Status:    db.HoldStatusActive,
+	}
+
+	testCases := []struct {
+		name          string
+		body          fiber.Map
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, response *http.Response)
+	}{
+		{
+			name:     "OK",
+			body:     fiber.Map{"to_account_id": toAccount.ID, "amount": 30},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CaptureHoldTxParams{
+					HoldID:      hold.ID,
+					ToAccountID: toAccount.ID,
+					Amount:      30,
+				}
+
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.CaptureHoldTxResult{Hold: hold}, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			body:     fiber.Map{"to_account_id": toAccount.ID},
+			username: "unauthorized_user",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			name:     "HoldNotFound",
+			body:     fiber.Map{"to_account_id": toAccount.ID},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(db.Hold{}, sql.ErrNoRows)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusNotFound, response.StatusCode)
+			},
+		},
+		{
+			name:     "MissingToAccount",
+			body:     fiber.Map{},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name:     "ToAccountCurrencyMismatch",
+			body:     fiber.Map{"to_account_id": toAccount.ID},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				otherAccount := toAccount
+				otherAccount.Currency = "KRW"
+				if account.Currency == "KRW" {
+					otherAccount.Currency = "USD"
+				}
+
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(otherAccount, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name:     "HoldNotActive",
+			body:     fiber.Map{"to_account_id": toAccount.ID},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CaptureHoldTxResult{}, db.ErrHoldNotActive)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusConflict, response.StatusCode)
+			},
+		},
+		{
+			name:     "AmountExceedsHold",
+			body:     fiber.Map{"to_account_id": toAccount.ID, "amount": 500},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CaptureHoldTxResult{}, db.ErrInvalidCaptureAmount)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+			},
+		},
+		{
+			name:     "TransferLimitExceeded",
+			body:     fiber.Map{"to_account_id": toAccount.ID},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CaptureHoldTxResult{}, db.ErrTransferLimitExceeded)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/holds/%d/capture", hold.ID)
+			request := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.CustomerRole, time.Minute)
+
+			response, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, response)
+		})
+	}
+}
+
+func TestReleaseHoldAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	hold := db.Hold{
+		ID:        util.RandomInt(1, 1000),
+		AccountID: account.ID,
+		Amount:    50,
+		Status:    db.HoldStatusActive,
+	}
+	released := hold
+	released.Status = db.HoldStatusReleased
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, response *http.Response)
+	}{
+		{
+			name: "Teller",
+			role: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ReleaseHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(released, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+
+				data, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+
+				var gotHold db.Hold
+				err = json.Unmarshal(data, &gotHold)
+				require.NoError(t, err)
+				require.Equal(t, db.HoldStatusReleased, gotHold.Status)
+			},
+		},
+		{
+			name: "HoldNotActive",
+			role: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ReleaseHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(db.Hold{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusConflict, response.StatusCode)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			role: util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ReleaseHold(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			url := fmt.Sprintf("/holds/%d/release", hold.ID)
+			request := httptest.NewRequest(http.MethodPost, url, nil)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "someone_else", tc.role, time.Minute)
+
+			response, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, response)
+		})
+	}
+}
+
+func TestReleaseHoldInvalidIDAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetHold(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+
+	request := httptest.NewRequest(http.MethodPost, "/holds/0/release", nil)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.TellerRole, time.Minute)
+
+	response, err := server.router.Test(request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestListActiveHoldsAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	holds := []db.Hold{{ID: 1, AccountID: account.ID, Amount: 10, Status: db.HoldStatusActive}}
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	store.EXPECT().ListActiveHolds(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(holds, nil)
+
+	server := newTestServer(t, store)
+
+	url := fmt.Sprintf("/accounts/%d/holds", account.ID)
+	request := httptest.NewRequest(http.MethodGet, url, nil)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+
+	response, err := server.router.Test(request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	data, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	var gotHolds []db.Hold
+	err = json.Unmarshal(data, &gotHolds)
+	require.NoError(t, err)
+	require.Len(t, gotHolds, 1)
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	db "simple_bank/db/sqlc"
+	"simple_bank/fx"
+	"simple_bank/revocation"
+	"simple_bank/util"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := util.Config{
+		TokenSymmetricKey:      util.RandomString(32),
+		AccessTokenDuration:    time.Minute,
+		RefreshTokenDuration:   time.Minute,
+		IdempotencyKeyDuration: time.Minute,
+		FXQuoteDuration:        time.Minute,
+		HoldDuration:           time.Minute,
+	}
+
+	server, err := NewServer(config, store, revocation.NewList(store))
+	require.NoError(t, err)
+
+	server.rateProvider, err = fx.NewStaticProvider(
+		fx.Rate{From: "USD", To: "EUR", Value: "0.9"},
+		fx.Rate{From: "EUR", To: "USD", Value: "1.1"},
+		fx.Rate{From: "KRW", To: "USD", Value: "0.0007692"},
+	)
+	require.NoError(t, err)
+
+	return server
+}
@@ -1,9 +1,10 @@
 package api
 
 import (
-	"errors"
-	"fmt"
+	"simple_bank/apierror"
+	"simple_bank/revocation"
 	"simple_bank/token"
+	"simple_bank/util"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -15,33 +16,59 @@ const (
 	authorizationPayloadKey = "authorization_payload"
 )
 
-func authMiddleware(tokenMaker token.Maker) fiber.Handler {
+func authMiddleware(tokenMaker token.Maker, revocations *revocation.List) fiber.Handler {
 
 	return func(ctx *fiber.Ctx) error {
 		authorizationHeader := ctx.GetReqHeaders()[authorizationHeaderKey]
 		if len(authorizationHeader) == 0 {
-			err := errors.New("authorization header is not provided")
-			return ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+			return apierror.New(fiber.StatusUnauthorized, apierror.CodeUnauthorized, "authorization header is not provided")
 		}
 
 		fields := strings.Fields(authorizationHeader)
 		if len(fields) < 2 {
-			err := errors.New("invalid athorization header format")
-			return ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+			return apierror.New(fiber.StatusUnauthorized, apierror.CodeUnauthorized, "invalid authorization header format")
 		}
 		authorizationType := strings.ToLower(fields[0])
 		if authorizationType != authorizationTypeBearer {
-			err := fmt.Errorf("unsupported authorization type: %s", authorizationType)
-			return ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+			return apierror.Newf(fiber.StatusUnauthorized, apierror.CodeUnauthorized, "unsupported authorization type: %s", authorizationType)
 		}
 
 		accessToken := fields[1]
 		payload, err := tokenMaker.VerifyToken(accessToken)
 		if err != nil {
-			return ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+			return err
+		}
+
+		if err := payload.CheckType(token.TypeAccess); err != nil {
+			return err
+		}
+
+		if revocations.IsRevoked(payload.ID) {
+			return revocation.ErrRevokedToken
 		}
 
 		ctx.Locals(authorizationPayloadKey, payload)
 		return ctx.Next()
 	}
 }
+
+// requireRole only lets through requests whose token carries one of the given roles.
+// It must run after authMiddleware.
+func requireRole(roles ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
+
+		for _, role := range roles {
+			if authPayload.Role == role {
+				return ctx.Next()
+			}
+		}
+
+		return apierror.Newf(fiber.StatusForbidden, apierror.CodeForbidden, "role %q is not allowed to access this resource", authPayload.Role)
+	}
+}
+
+// isStaff reports whether the payload belongs to a banker who may access any customer's accounts
+func isStaff(payload *token.Payload) bool {
+	return payload.Role == util.TellerRole || payload.Role == util.AdminRole
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"simple_bank/apierror"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseRequest fills req from the query string, the body and the route params, in that order, then
+// validates it. Fields are bound to route params with a `params:"name"` tag.
+func (server *Server) parseRequest(ctx *fiber.Ctx, req interface{}) error {
+	if err := ctx.QueryParser(req); err != nil {
+		return apierror.InvalidQuery(err)
+	}
+
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(req); err != nil {
+			return apierror.InvalidBody(err)
+		}
+	}
+
+	if err := parseParams(ctx, req); err != nil {
+		return err
+	}
+
+	return server.validate.Struct(req)
+}
+
+func parseParams(ctx *fiber.Ctx, req interface{}) error {
+	value := reflect.ValueOf(req).Elem()
+
+	for i := 0; i < value.NumField(); i++ {
+		name := value.Type().Field(i).Tag.Get("params")
+		if len(name) == 0 {
+			continue
+		}
+
+		field := value.Field(i)
+		param := ctx.Params(name)
+
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(param)
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(param, 10, field.Type().Bits())
+			if err != nil {
+				return apierror.InvalidParam(name, err)
+			}
+			field.SetInt(n)
+		default:
+			panic(fmt.Sprintf("unsupported params field type %s", field.Type()))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"simple_bank/apierror"
+	"simple_bank/currency"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+type testRequest struct {
+	AccountID int64  `params:"id" validate:"required,min=1"`
+	Owner     string `params:"owner" validate:"required"`
+	PageSize  int32  `query:"page_size" validate:"omitempty,min=5,max=10"`
+	Currency  string `json:"currency" validate:"required,currency"`
+}
+
+func TestParseRequest(t *testing.T) {
+	testCases := []struct {
+		name          string
+		url           string
+		body          string
+		checkResponse func(t *testing.T, req *testRequest, err error)
+	}{
+		{
+			name: "OK",
+			url:  "/accounts/7/alice?page_size=5",
+			body: `{"currency": "USD"}`,
+			checkResponse: func(t *testing.T, req *testRequest, err error) {
+				require.NoError(t, err)
+				require.Equal(t, testRequest{AccountID: 7, Owner: "alice", PageSize: 5, Currency: "USD"}, *req)
+			},
+		},
+		{
+			name: "ParamsOverrideBody",
+			url:  "/accounts/7/alice",
+			body: `{"currency": "USD", "AccountID": 8}`,
+			checkResponse: func(t *testing.T, req *testRequest, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(7), req.AccountID)
+			},
+		},
+		{
+			name: "InvalidParam",
+			url:  "/accounts/abc/alice",
+			body: `{"currency": "USD"}`,
+			checkResponse: func(t *testing.T, req *testRequest, err error) {
+				requireFieldErrors(t, err, apierror.FieldError{Field: "id", Reason: "is malformed"})
+			},
+		},
+		{
+			name: "InvalidQuery",
+			url:  "/accounts/7/alice?page_size=abc",
+			body: `{"currency": "USD"}`,
+			checkResponse: func(t *testing.T, req *testRequest, err error) {
+				apiErr := apierror.From(err)
+				require.Equal(t, http.StatusBadRequest, apiErr.Status)
+				require.Equal(t, apierror.CodeInvalidRequest, apiErr.Code)
+			},
+		},
+		{
+			name: "InvalidBody",
+			url:  "/accounts/7/alice",
+			body: `{invalid`,
+			checkResponse: func(t *testing.T, req *testRequest, err error) {
+				apiErr := apierror.From(err)
+				require.Equal(t, http.StatusBadRequest, apiErr.Status)
+				require.Equal(t, apierror.CodeInvalidRequest, apiErr.Code)
+			},
+		},
+		{
+			name: "ValidationFailed",
+			url:  "/accounts/0/alice?page_size=20",
+			body: `{"currency": "GBP"}`,
+			checkResponse: func(t *testing.T, req *testRequest, err error) {
+				requireFieldErrors(t, err,
+					apierror.FieldError{Field: "id", Reason: "is required"},
+					apierror.FieldError{Field: "page_size", Reason: "must be at most 10"},
+					apierror.FieldError{Field: "currency", Reason: "must be a supported currency"},
+				)
+			},
+		},
+		{
+			name: "MissingBody",
+			url:  "/accounts/7/alice",
+			checkResponse: func(t *testing.T, req *testRequest, err error) {
+				requireFieldErrors(t, err, apierror.FieldError{Field: "currency", Reason: "is required"})
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			currencies, err := currency.NewRegistry(nil)
+			require.NoError(t, err)
+			server := &Server{validate: newValidator(currencies)}
+			router := fiber.New()
+
+			var req *testRequest
+			var parseErr error
+			router.Post("/accounts/:id/:owner", func(ctx *fiber.Ctx) error {
+				req = new(testRequest)
+				parseErr = server.parseRequest(ctx, req)
+				return ctx.SendStatus(fiber.StatusNoContent)
+			})
+
+			request := httptest.NewRequest(http.MethodPost, tc.url, strings.NewReader(tc.body))
+			request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+			response, err := router.Test(request)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusNoContent, response.StatusCode)
+			tc.checkResponse(t, req, parseErr)
+		})
+	}
+}
+
+func requireFieldErrors(t *testing.T, err error, details ...apierror.FieldError) {
+	apiErr := apierror.From(err)
+	require.Equal(t, http.StatusBadRequest, apiErr.Status)
+	require.Equal(t, apierror.CodeValidationFailed, apiErr.Code)
+	require.Equal(t, details, apiErr.Details)
+}
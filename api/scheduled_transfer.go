@@ -0,0 +1,254 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"simple_bank/apierror"
+	db "simple_bank/db/sqlc"
+	"simple_bank/schedule"
+	"simple_bank/token"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var errScheduledTransferNotActive = errors.New("scheduled transfer has already completed or been cancelled")
+
+type createScheduledTransferRequest struct {
+	FromAccountID int64  `json:"from_account_id" validate:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" validate:"required,min=1"`
+	Amount        int64  `json:"amount" validate:"required,gt=0"`
+	Currency      string `json:"currency" validate:"required,currency"`
+	// Schedule is a recurrence rule such as "FREQ=MONTHLY;BYMONTHDAY=1", empty for a one-off transfer
+	Schedule string `json:"schedule"`
+	// StartAt defaults to now
+	StartAt *time.Time `json:"start_at"`
+}
+
+func (server *Server) createScheduledTransfer(ctx *fiber.Ctx) error {
+	req := new(createScheduledTransferRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	spec, firstRunAt, err := planScheduledTransfer(req.Schedule, req.StartAt)
+	if err != nil {
+		return err
+	}
+
+	fromAccount, err := server.validateAccount(ctx, req.FromAccountID, req.Currency)
+	if err != nil {
+		return err
+	}
+
+	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
+
+	if authPayload.Username != fromAccount.Owner {
+		return errAccountNotOwned
+	}
+
+	toAccount, err := server.store.GetAccount(ctx.Context(), req.ToAccountID)
+	if err != nil {
+		return apierror.Wrap(fiber.StatusBadRequest, apierror.CodeAccountNotFound, "invalid to_account", err)
+	}
+
+	// scheduled transfers run without a quote, so they stay in one currency
+	if toAccount.Currency != fromAccount.Currency {
+		return currencyMismatch(toAccount, fromAccount.Currency)
+	}
+
+	scheduled, err := server.store.CreateScheduledTransfer(ctx.Context(), db.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        req.Amount,
+		Currency:      fromAccount.Currency,
+		Schedule:      spec,
+		NextRunAt:     &firstRunAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(scheduled)
+}
+
+// planScheduledTransfer normalises the schedule and works out the first run
+func planScheduledTransfer(spec string, startAt *time.Time) (string, time.Time, error) {
+	start := time.Now()
+	if startAt != nil {
+		if startAt.Before(start) {
+			apiErr := apierror.New(fiber.StatusBadRequest, apierror.CodeValidationFailed, "request validation failed")
+			apiErr.Details = []apierror.FieldError{{Field: "start_at", Reason: "must not be in the past"}}
+			return "", time.Time{}, apiErr
+		}
+		start = *startAt
+	}
+
+	return schedule.Plan(spec, start)
+}
+
+type listScheduledTransfersRequest struct {
+	PageID   int32 `query:"page_id" validate:"required,number,min=1"`
+	PageSize int32 `query:"page_size" validate:"required,number,min=5,max=10"`
+}
+
+func (server *Server) listScheduledTransfers(ctx *fiber.Ctx) error {
+	req := new(listScheduledTransfersRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
+
+	scheduled, err := server.store.ListScheduledTransfers(ctx.Context(), db.ListScheduledTransfersParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(scheduled)
+}
+
+// authorizedScheduledTransfer loads the scheduled transfer and checks the authenticated user owns it
+func (server *Server) authorizedScheduledTransfer(ctx *fiber.Ctx, id int64) (db.ScheduledTransfer, error) {
+	scheduled, err := server.store.GetScheduledTransfer(ctx.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.ScheduledTransfer{}, apierror.New(fiber.StatusNotFound, apierror.CodeScheduledTransferNotFound, "scheduled transfer not found")
+		}
+		return db.ScheduledTransfer{}, err
+	}
+
+	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
+
+	if authPayload.Username != scheduled.Owner && !isStaff(authPayload) {
+		return db.ScheduledTransfer{}, apierror.New(fiber.StatusUnauthorized, apierror.CodeResourceNotOwned, "scheduled transfer doesn't belong to the authenticated user")
+	}
+
+	return scheduled, nil
+}
+
+type getScheduledTransferRequest struct {
+	ID int64 `params:"id" validate:"required,min=1"`
+}
+
+func (server *Server) getScheduledTransfer(ctx *fiber.Ctx) error {
+	req := new(getScheduledTransferRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	scheduled, err := server.authorizedScheduledTransfer(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(scheduled)
+}
+
+type updateScheduledTransferRequest struct {
+	ID       int64      `params:"id" validate:"required,min=1"`
+	Amount   int64      `json:"amount" validate:"required,gt=0"`
+	Schedule string     `json:"schedule"`
+	StartAt  *time.Time `json:"start_at"`
+}
+
+// updateScheduledTransfer replaces the amount and schedule of an active scheduled transfer
+func (server *Server) updateScheduledTransfer(ctx *fiber.Ctx) error {
+	req := new(updateScheduledTransferRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	spec, firstRunAt, err := planScheduledTransfer(req.Schedule, req.StartAt)
+	if err != nil {
+		return err
+	}
+
+	scheduled, err := server.authorizedScheduledTransfer(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	scheduled, err = server.store.UpdateScheduledTransfer(ctx.Context(), db.UpdateScheduledTransferParams{
+		ID:        scheduled.ID,
+		Amount:    req.Amount,
+		Schedule:  spec,
+		NextRunAt: &firstRunAt,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errScheduledTransferNotActive
+		}
+		return err
+	}
+
+	return ctx.JSON(scheduled)
+}
+
+type cancelScheduledTransferRequest struct {
+	ID int64 `params:"id" validate:"required,min=1"`
+}
+
+// cancelScheduledTransfer stops future runs; the scheduled transfer and its runs are kept
+func (server *Server) cancelScheduledTransfer(ctx *fiber.Ctx) error {
+	req := new(cancelScheduledTransferRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	scheduled, err := server.authorizedScheduledTransfer(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = server.store.CancelScheduledTransfer(ctx.Context(), scheduled.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errScheduledTransferNotActive
+		}
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+type listScheduledTransferRunsRequest struct {
+	ID       int64 `params:"id" validate:"required,min=1"`
+	PageID   int32 `query:"page_id" validate:"required,number,min=1"`
+	PageSize int32 `query:"page_size" validate:"required,number,min=5,max=10"`
+}
+
+// listScheduledTransferRuns lists the outcome of each run, newest first
+func (server *Server) listScheduledTransferRuns(ctx *fiber.Ctx) error {
+	req := new(listScheduledTransferRunsRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	scheduled, err := server.authorizedScheduledTransfer(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(ctx.Context(), db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		Limit:               req.PageSize,
+		Offset:              (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(runs)
+}
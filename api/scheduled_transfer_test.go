@@ -0,0 +1,425 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple_bank/db/mock"
+	db "simple_bank/db/sqlc"
+	"simple_bank/util"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func randomScheduledTransfer(owner string, fromAccount, toAccount db.Account) db.ScheduledTransfer {
+	nextRunAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	return db.ScheduledTransfer{
+		ID:            util.RandomInt(1, 1000),
+		Owner:         owner,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        util.RandomMoney(),
+		Currency:      fromAccount.Currency,
+		Schedule:      "FREQ=MONTHLY;BYMONTHDAY=1",
+		Status:        db.ScheduledTransferStatusActive,
+		NextRunAt:     &nextRunAt,
+	}
+}
+
+func TestCreateScheduledTransferAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	fromAccount := randomAccount(user.Username)
+	toAccount := randomAccount(util.RandomOwner())
+	toAccount.Currency = fromAccount.Currency
+	startAt := time.Date(2100, time.March, 15, 9, 0, 0, 0, time.UTC)
+	firstRunAt := time.Date(2100, time.April, 1, 9, 0, 0, 0, time.UTC)
+	amount := int64(100)
+
+	testCases := []struct {
+		name          string
+		body          fiber.Map
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, response *http.Response)
+	}{
+		{
+			name: "OK",
+			body: fiber.Map{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amount,
+				"currency":        fromAccount.Currency,
+				"schedule":        "FREQ=MONTHLY;BYMONTHDAY=1",
+				"start_at":        startAt,
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateScheduledTransferParams{
+					Owner:         user.Username,
+					FromAccountID: fromAccount.ID,
+					ToAccountID:   toAccount.ID,
+					Amount:        amount,
+					Currency:      fromAccount.Currency,
+					Schedule:      "FREQ=MONTHLY;BYMONTHDAY=1",
+					NextRunAt:     &firstRunAt,
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					CreateScheduledTransfer(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ScheduledTransfer{ID: 1, NextRunAt: &firstRunAt}, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+
+				data, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+
+				var gotScheduled db.ScheduledTransfer
+				err = json.Unmarshal(data, &gotScheduled)
+				require.NoError(t, err)
+				require.Equal(t, firstRunAt, gotScheduled.NextRunAt.UTC())
+			},
+		},
+		{
+			name: "InvalidSchedule",
+			body: fiber.Map{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amount,
+				"currency":        fromAccount.Currency,
+				"schedule":        "FREQ=HOURLY",
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name: "StartAtInThePast",
+			body: fiber.Map{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amount,
+				"currency":        fromAccount.Currency,
+				"start_at":        time.Now().Add(-time.Hour),
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			body: fiber.Map{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amount,
+				"currency":        fromAccount.Currency,
+			},
+			username: "unauthorized_user",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			name: "ToAccountCurrencyMismatch",
+			body: fiber.Map{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amount,
+				"currency":        fromAccount.Currency,
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				otherAccount := toAccount
+				otherAccount.Currency = "KRW"
+				if fromAccount.Currency == "KRW" {
+					otherAccount.Currency = "USD"
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(otherAccount, nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name: "InvalidAmount",
+			body: fiber.Map{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          -1,
+				"currency":        fromAccount.Currency,
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name: "InternalError",
+			body: fiber.Map{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amount,
+				"currency":        fromAccount.Currency,
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(1).Return(db.ScheduledTransfer{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, response.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request := httptest.NewRequest(http.MethodPost, "/scheduled-transfers", bytes.NewReader(data))
+			request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.CustomerRole, time.Minute)
+
+			response, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, response)
+		})
+	}
+}
+
+func TestManageScheduledTransferAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	fromAccount := randomAccount(user.Username)
+	toAccount := randomAccount(util.RandomOwner())
+	scheduled := randomScheduledTransfer(user.Username, fromAccount, toAccount)
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		body          fiber.Map
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, response *http.Response)
+	}{
+		{
+			name:     "Get",
+			method:   http.MethodGet,
+			url:      fmt.Sprintf("/scheduled-transfers/%d", scheduled.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+			},
+		},
+		{
+			name:     "GetNotFound",
+			method:   http.MethodGet,
+			url:      fmt.Sprintf("/scheduled-transfers/%d", scheduled.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(db.ScheduledTransfer{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusNotFound, response.StatusCode)
+			},
+		},
+		{
+			name:     "GetUnauthorizedUser",
+			method:   http.MethodGet,
+			url:      fmt.Sprintf("/scheduled-transfers/%d", scheduled.ID),
+			username: "unauthorized_user",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			name:     "Update",
+			method:   http.MethodPut,
+			url:      fmt.Sprintf("/scheduled-transfers/%d", scheduled.ID),
+			body:     fiber.Map{"amount": 50, "schedule": "FREQ=WEEKLY;BYDAY=MO"},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().
+					UpdateScheduledTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.UpdateScheduledTransferParams) (db.ScheduledTransfer, error) {
+						require.Equal(t, scheduled.ID, arg.ID)
+						require.Equal(t, int64(50), arg.Amount)
+						require.Equal(t, "FREQ=WEEKLY;BYDAY=MO", arg.Schedule)
+						require.Equal(t, time.Monday, arg.NextRunAt.Weekday())
+						return scheduled, nil
+					})
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+			},
+		},
+		{
+			name:     "UpdateNotActive",
+			method:   http.MethodPut,
+			url:      fmt.Sprintf("/scheduled-transfers/%d", scheduled.ID),
+			body:     fiber.Map{"amount": 50},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().UpdateScheduledTransfer(gomock.Any(), gomock.Any()).Times(1).Return(db.ScheduledTransfer{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusConflict, response.StatusCode)
+			},
+		},
+		{
+			name:     "Cancel",
+			method:   http.MethodDelete,
+			url:      fmt.Sprintf("/scheduled-transfers/%d", scheduled.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().CancelScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusNoContent, response.StatusCode)
+			},
+		},
+		{
+			name:     "CancelNotActive",
+			method:   http.MethodDelete,
+			url:      fmt.Sprintf("/scheduled-transfers/%d", scheduled.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().CancelScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(db.ScheduledTransfer{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusConflict, response.StatusCode)
+			},
+		},
+		{
+			name:     "ListRuns",
+			method:   http.MethodGet,
+			url:      fmt.Sprintf("/scheduled-transfers/%d/runs?page_id=1&page_size=5", scheduled.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListScheduledTransferRunsParams{
+					ScheduledTransferID: scheduled.ID,
+					Limit:               5,
+					Offset:              0,
+				}
+
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().
+					ListScheduledTransferRuns(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.ScheduledTransferRun{{ID: 1, Status: db.ScheduledRunStatusFailed, FailureReason: "insufficient funds"}}, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+
+				data, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+
+				var gotRuns []db.ScheduledTransferRun
+				err = json.Unmarshal(data, &gotRuns)
+				require.NoError(t, err)
+				require.Len(t, gotRuns, 1)
+				require.Equal(t, "insufficient funds", gotRuns[0].FailureReason)
+			},
+		},
+		{
+			name:     "List",
+			method:   http.MethodGet,
+			url:      "/scheduled-transfers?page_id=1&page_size=5",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListScheduledTransfersParams{
+					Owner:  user.Username,
+					Limit:  5,
+					Offset: 0,
+				}
+
+				store.EXPECT().ListScheduledTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.ScheduledTransfer{scheduled}, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			var body io.Reader
+			if tc.body != nil {
+				data, err := json.Marshal(tc.body)
+				require.NoError(t, err)
+				body = bytes.NewReader(data)
+			}
+
+			request := httptest.NewRequest(tc.method, tc.url, body)
+			request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.CustomerRole, time.Minute)
+
+			response, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, response)
+		})
+	}
+}
@@ -1,32 +1,80 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"simple_bank/currency"
 	db "simple_bank/db/sqlc"
+	"simple_bank/fx"
+	"simple_bank/hold"
+	"simple_bank/outbox"
+	"simple_bank/revocation"
+	"simple_bank/schedule"
+	"simple_bank/statement"
 	"simple_bank/token"
 	"simple_bank/util"
+	"simple_bank/webhook"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 )
 
 type Server struct {
-	config     util.Config
-	store      *db.Store
-	router     *fiber.App
-	tokenMaker token.Maker
+	config       util.Config
+	store        db.Store
+	router       *fiber.App
+	tokenMaker   token.Maker
+	currencies   *currency.Registry
+	validate     *validator.Validate
+	revocations  *revocation.List
+	rateProvider fx.RateProvider
+	statements   *statement.Service
+	holdExpirer  *hold.Expirer
+	scheduler    *schedule.Runner
+	outboxRelay  *outbox.Relay
+	webhooks     *webhook.Deliverer
 }
 
-func NewServer(config util.Config, store *db.Store) (*Server, error) {
-	tokenMaker, err := token.NewJWTMaker(config.TokenSymmetricKey)
+// NewServer creates the HTTP server. revocations is shared with the gRPC server, so a token revoked by
+// logging out is refused by both at once.
+func NewServer(config util.Config, store db.Store, revocations *revocation.List) (*Server, error) {
+	tokenMaker, err := token.NewMaker(config)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	rateProvider, err := fx.NewRateProvider(config, store)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create rate provider: %w", err)
+	}
+
+	currencies, err := currency.NewRegistry(config.SupportedCurrencies)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create currency registry: %w", err)
+	}
+
+	publisher, err := outbox.NewPublisher(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create outbox publisher: %w", err)
+	}
+	// subscribers' webhooks are queued alongside the configured publisher
+	publishers := outbox.MultiPublisher{publisher, webhook.NewDispatcher(store)}
+
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
+		config:       config,
+		store:        store,
+		tokenMaker:   tokenMaker,
+		currencies:   currencies,
+		validate:     newValidator(currencies),
+		revocations:  revocations,
+		rateProvider: rateProvider,
+		statements:   statement.NewService(store),
+		holdExpirer:  hold.NewExpirer(store),
+		scheduler:    schedule.NewRunner(store),
+		outboxRelay:  outbox.NewRelay(store, publishers),
+		webhooks:     webhook.NewDeliverer(store),
 	}
 	// router := fiber.New()
 
@@ -50,22 +98,61 @@ func NewServer(config util.Config, store *db.Store) (*Server, error) {
 }
 
 func (server *Server) setupRouter() {
-	router := fiber.New()
+	router := fiber.New(fiber.Config{ErrorHandler: errorHandler})
 
+	router.Use(requestid.New())
 	router.Use(logger.New())
 	router.Get("/ping", func(c *fiber.Ctx) error {
 		return c.JSON("pong")
 	})
 	router.Post("/users", server.createUser)
 	router.Post("/users/login", server.loginUser)
+	router.Post("/tokens/renew_access", server.renewAccessToken)
+	router.Get("/.well-known/jwks.json", server.getJWKS)
+	router.Get("/currencies", server.listCurrencies)
+	router.Get("/openapi.json", server.getOpenAPISpec)
+	router.Get("/docs", server.getDocs)
+	router.Use("/docs", swaggerUIAssets())
 
-	authRoutes := router.Group("/", authMiddleware(server.tokenMaker))
+	authRoutes := router.Group("/", authMiddleware(server.tokenMaker, server.revocations))
 
+	authRoutes.Post("/users/logout", server.logoutUser)
+	authRoutes.Patch("/users/:username/role", requireRole(util.AdminRole), server.updateUserRole)
 	authRoutes.Post("/accounts", server.createAccount)
 	authRoutes.Get("/account/:id", server.getAccount)
 	authRoutes.Get("/accounts", server.listAccounts)
-	authRoutes.Put("/account/:id", server.updateAccount)
+	authRoutes.Get("/accounts/all", requireRole(util.TellerRole, util.AdminRole), server.listAllAccounts)
+	authRoutes.Put("/account/:id", requireRole(util.AdminRole), server.updateAccount)
+	authRoutes.Patch("/accounts/:id/overdraft_limit", requireRole(util.AdminRole), server.updateOverdraftLimit)
+	authRoutes.Get("/users/:username/transfer_limits", requireRole(util.AdminRole), server.listUserTransferLimits)
+	authRoutes.Put("/users/:username/transfer_limits", requireRole(util.AdminRole), server.setUserTransferLimit)
+	authRoutes.Get("/accounts/:id/transfer_limits", requireRole(util.AdminRole), server.listAccountTransferLimits)
+	authRoutes.Put("/accounts/:id/transfer_limits", requireRole(util.AdminRole), server.setAccountTransferLimit)
+	authRoutes.Delete("/transfer_limits/:id", requireRole(util.AdminRole), server.deleteTransferLimit)
 	authRoutes.Post("/transfers", server.createTransfer)
+	authRoutes.Post("/transfers/:id/reverse", requireRole(util.AdminRole), server.reverseTransfer)
+	authRoutes.Post("/fx/quotes", server.createFxQuote)
+	authRoutes.Post("/accounts/:id/deposits", server.createDeposit)
+	authRoutes.Post("/accounts/:id/withdrawals", server.createWithdrawal)
+	authRoutes.Get("/accounts/:id/statement", server.getStatement)
+	authRoutes.Post("/accounts/:id/holds", server.placeHold)
+	authRoutes.Get("/accounts/:id/holds", server.listActiveHolds)
+	authRoutes.Post("/holds/:id/capture", server.captureHold)
+	authRoutes.Post("/holds/:id/release", server.releaseHold)
+	authRoutes.Post("/scheduled-transfers", server.createScheduledTransfer)
+	authRoutes.Get("/scheduled-transfers", server.listScheduledTransfers)
+	authRoutes.Get("/scheduled-transfers/:id", server.getScheduledTransfer)
+	authRoutes.Put("/scheduled-transfers/:id", server.updateScheduledTransfer)
+	authRoutes.Delete("/scheduled-transfers/:id", server.cancelScheduledTransfer)
+	authRoutes.Get("/scheduled-transfers/:id/runs", server.listScheduledTransferRuns)
+	authRoutes.Post("/webhooks", server.createWebhook)
+	authRoutes.Get("/webhooks", server.listWebhooks)
+	authRoutes.Delete("/webhooks/:id", server.deleteWebhook)
+	authRoutes.Get("/webhooks/:id/deliveries", server.listWebhookDeliveries)
+	authRoutes.Post("/webhooks/:id/deliveries/:delivery_id/replay", server.replayWebhookDelivery)
+	authRoutes.Get("/accounts/:id/entries", server.listAccountEntries)
+	authRoutes.Get("/accounts/:id/transfers", server.listAccountTransfers)
+	authRoutes.Post("/accounts/:id/corrections", requireRole(util.TellerRole, util.AdminRole), server.createBalanceCorrection)
 
 	// router.Post("/accounts", server.createAccount)
 	// router.Get("/account/:id", server.getAccount)
@@ -78,11 +165,10 @@ func (server *Server) setupRouter() {
 }
 
 func (server *Server) Start(address string) error {
-	return server.router.Listen(address)
-}
+	go server.holdExpirer.Run(context.Background(), server.config.HoldExpiryInterval)
+	go server.scheduler.Run(context.Background(), server.config.ScheduledTransferInterval)
+	go server.outboxRelay.Run(context.Background(), server.config.OutboxRelayInterval)
+	go server.webhooks.Run(context.Background(), server.config.WebhookDeliveryInterval)
 
-func errorResponse(err error) *fiber.Map {
-	return &fiber.Map{
-		"error": err.Error(),
-	}
+	return server.router.Listen(address)
 }
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"simple_bank/apierror"
+	"simple_bank/export"
+	"simple_bank/token"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const statementDateLayout = "2006-01-02"
+
+type getStatementRequest struct {
+	AccountID int64  `params:"id" validate:"required,min=1"`
+	From      string `query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To        string `query:"to" validate:"omitempty,datetime=2006-01-02"`
+	Format    string `query:"format" validate:"omitempty,oneof=json csv ofx camt053"`
+}
+
+// period returns the half-open interval covered by the inclusive from/to dates. It defaults
+// to the current month up to today.
+func (req *getStatementRequest) period() (from time.Time, to time.Time) {
+	to = time.Now().UTC().Truncate(24 * time.Hour)
+	if len(req.To) > 0 {
+		to, _ = time.Parse(statementDateLayout, req.To)
+	}
+
+	from = time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if len(req.From) > 0 {
+		from, _ = time.Parse(statementDateLayout, req.From)
+	}
+
+	return from, to.AddDate(0, 0, 1)
+}
+
+// exportFormat picks the statement format from the format query, falling back to the Accept header.
+// An empty format means JSON.
+func (req *getStatementRequest) exportFormat(ctx *fiber.Ctx) (export.Format, bool) {
+	if len(req.Format) > 0 {
+		if req.Format == "json" {
+			return "", true
+		}
+		return export.Format(req.Format), true
+	}
+
+	mediaType := ctx.Accepts(fiber.MIMEApplicationJSON, "text/csv", "application/x-ofx", "application/vnd.iso20022.camt.053+xml")
+	if len(mediaType) == 0 {
+		return "", false
+	}
+	return export.MediaTypes[mediaType], true
+}
+
+func (server *Server) getStatement(ctx *fiber.Ctx) error {
+	req := new(getStatementRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	format, ok := req.exportFormat(ctx)
+	if !ok {
+		return export.ErrUnsupportedFormat
+	}
+
+	account, err := server.store.GetAccount(ctx.Context(), req.AccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errAccountNotFound
+		}
+		return err
+	}
+
+	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
+
+	if authPayload.Username != account.Owner && !isStaff(authPayload) {
+		return errAccountNotOwned
+	}
+
+	from, to := req.period()
+	result, err := server.statements.Generate(ctx.Context(), account.ID, from, to)
+	if err != nil {
+		return err
+	}
+
+	if len(format) == 0 {
+		return ctx.JSON(result)
+	}
+
+	writer, err := export.NewWriter(format)
+	if err != nil {
+		return apierror.Wrap(fiber.StatusBadRequest, apierror.CodeInvalidRequest, err.Error(), err)
+	}
+
+	filename := fmt.Sprintf("statement-%d-%s.%s", account.ID, from.Format(statementDateLayout), writer.FileExtension())
+	ctx.Attachment(filename)
+	ctx.Set(fiber.HeaderContentType, writer.ContentType())
+
+	return writer.Write(ctx, result)
+}
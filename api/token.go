@@ -0,0 +1,93 @@
+package api
+
+import (
+	"database/sql"
+	"simple_bank/apierror"
+	"simple_bank/token"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type renewAccessTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type renewAccessTokenResponse struct {
+	AccessToken          string    `json:"access_token"`
+	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+}
+
+func (server *Server) renewAccessToken(ctx *fiber.Ctx) error {
+	req := new(renewAccessTokenRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	if err := refreshPayload.CheckType(token.TypeRefresh); err != nil {
+		return err
+	}
+
+	session, err := server.store.GetSession(ctx.Context(), refreshPayload.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return apierror.New(fiber.StatusNotFound, apierror.CodeSessionNotFound, "session not found")
+		}
+		return err
+	}
+
+	if session.IsBlocked {
+		return apierror.New(fiber.StatusUnauthorized, apierror.CodeSessionBlocked, "blocked session")
+	}
+
+	if session.Username != refreshPayload.Username {
+		return apierror.New(fiber.StatusUnauthorized, apierror.CodeInvalidToken, "incorrect session user")
+	}
+
+	if session.RefreshToken != req.RefreshToken {
+		return apierror.New(fiber.StatusUnauthorized, apierror.CodeInvalidToken, "mismatched session token")
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		return apierror.New(fiber.StatusUnauthorized, apierror.CodeInvalidToken, "expired session")
+	}
+
+	// the role may have changed since the refresh token was issued
+	user, err := server.store.GetUser(ctx.Context(), session.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errUserNotFound
+		}
+		return err
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, token.TypeAccess, server.config.AccessTokenDuration)
+	if err != nil {
+		return err
+	}
+
+	rsp := renewAccessTokenResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiredAt,
+	}
+
+	return ctx.JSON(rsp)
+}
+
+// getJWKS publishes the public keys that verify access tokens. Makers that sign with a
+// shared secret have nothing to publish and return an empty key set.
+func (server *Server) getJWKS(ctx *fiber.Ctx) error {
+	keySet := token.JWKSet{Keys: []token.JWK{}}
+	if provider, ok := server.tokenMaker.(token.KeySetProvider); ok {
+		keySet = provider.JWKS()
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return ctx.JSON(keySet)
+}
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"database/sql"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple_bank/db/mock"
+	db "simple_bank/db/sqlc"
+	"simple_bank/token"
+	"simple_bank/util"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenewAccessTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		buildSession  func(refreshToken string, payload *token.Payload) db.Session
+		body          func(refreshToken string) fiber.Map
+		sessionErr    error
+		expectSession bool
+		currentRole   string
+		checkResponse func(t *testing.T, tokenMaker token.Maker, response *http.Response)
+	}{
+		{
+			name: "OK",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				return newTestSession(refreshToken, payload)
+			},
+			body: func(refreshToken string) fiber.Map {
+				return fiber.Map{"refresh_token": refreshToken}
+			},
+			expectSession: true,
+			currentRole:   util.AdminRole,
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+
+				payload := requireBodyAccessToken(t, tokenMaker, response.Body)
+				require.Equal(t, user.Username, payload.Username)
+				require.Equal(t, util.AdminRole, payload.Role)
+			},
+		},
+		{
+			name: "RoleChangedSinceLogin",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				return newTestSession(refreshToken, payload)
+			},
+			body: func(refreshToken string) fiber.Map {
+				return fiber.Map{"refresh_token": refreshToken}
+			},
+			expectSession: true,
+			currentRole:   util.CustomerRole,
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+
+				payload := requireBodyAccessToken(t, tokenMaker, response.Body)
+				require.Equal(t, util.CustomerRole, payload.Role)
+			},
+		},
+		{
+			name: "MissingRefreshToken",
+			body: func(refreshToken string) fiber.Map {
+				return fiber.Map{}
+			},
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name: "InvalidRefreshToken",
+			body: func(refreshToken string) fiber.Map {
+				return fiber.Map{"refresh_token": "invalid"}
+			},
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, response *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			name: "SessionNotFound",
+			body: func(refreshToken string) fiber.Map {
+				return fiber.Map{"refresh_token": refreshToken}
+			},
+			sessionErr:    sql.ErrNoRows,
+			expectSession: true,
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, response *http.Response) {
+				require.Equal(t, http.StatusNotFound, response.StatusCode)
+			},
+		},
+		{
+			name: "GetSessionError",
+			body: func(refreshToken string) fiber.Map {
+				return fiber.Map{"refresh_token": refreshToken}
+			},
+			sessionErr:    sql.ErrConnDone,
+			expectSession: true,
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, response *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, response.StatusCode)
+			},
+		},
+		{
+			name: "BlockedSession",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				session := newTestSession(refreshToken, payload)
+				session.IsBlocked = true
+				return session
+			},
+			body: func(refreshToken string) fiber.Map {
+				return fiber.Map{"refresh_token": refreshToken}
+			},
+			expectSession: true,
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, response *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			name: "IncorrectSessionUser",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				session := newTestSession(refreshToken, payload)
+				session.Username = util.RandomOwner()
+				return session
+			},
+			body: func(refreshToken string) fiber.Map {
+				return fiber.Map{"refresh_token": refreshToken}
+			},
+			expectSession: true,
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, response *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			name: "MismatchedSessionToken",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				session := newTestSession(refreshToken, payload)
+				session.RefreshToken = "mismatched"
+				return session
+			},
+			body: func(refreshToken string) fiber.Map {
+				return fiber.Map{"refresh_token": refreshToken}
+			},
+			expectSession: true,
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, response *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			name: "ExpiredSession",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				session := newTestSession(refreshToken, payload)
+				session.ExpiresAt = time.Now().Add(-time.Minute)
+				return session
+			},
+			body: func(refreshToken string) fiber.Map {
+				return fiber.Map{"refresh_token": refreshToken}
+			},
+			expectSession: true,
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, response *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+
+			refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(user.Username, util.AdminRole, token.TypeRefresh, time.Minute)
+			require.NoError(t, err)
+
+			var session db.Session
+			if tc.buildSession != nil {
+				session = tc.buildSession(refreshToken, refreshPayload)
+			}
+
+			times := 0
+			if tc.expectSession {
+				times = 1
+			}
+			store.EXPECT().
+				GetSession(gomock.Any(), gomock.Eq(refreshPayload.ID)).
+				Times(times).
+				Return(session, tc.sessionErr)
+
+			if len(tc.currentRole) > 0 {
+				currentUser := user
+				currentUser.Role = tc.currentRole
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(currentUser, nil)
+			} else {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			}
+
+			data, err := json.Marshal(tc.body(refreshToken))
+			require.NoError(t, err)
+
+			request := httptest.NewRequest(http.MethodPost, "/tokens/renew_access", bytes.NewReader(data))
+			request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+			response, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, server.tokenMaker, response)
+		})
+	}
+}
+
+func TestRenewAccessTokenWithAccessToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+
+	accessToken, _, err := server.tokenMaker.CreateToken(util.RandomOwner(), util.CustomerRole, token.TypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(fiber.Map{"refresh_token": accessToken})
+	require.NoError(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/tokens/renew_access", bytes.NewReader(data))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+	response, err := server.router.Test(request)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+func requireBodyAccessToken(t *testing.T, tokenMaker token.Maker, body io.Reader) *token.Payload {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var rsp renewAccessTokenResponse
+	err = json.Unmarshal(data, &rsp)
+	require.NoError(t, err)
+	require.True(t, rsp.AccessTokenExpiresAt.After(time.Now()))
+
+	payload, err := tokenMaker.VerifyToken(rsp.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, token.TypeAccess, payload.Type)
+	return payload
+}
+
+func newTestSession(refreshToken string, payload *token.Payload) db.Session {
+	return db.Session{
+		ID:           payload.ID,
+		Username:     payload.Username,
+		RefreshToken: refreshToken,
+		ExpiresAt:    payload.ExpiredAt,
+	}
+}
+
+func TestGetJWKSAPI(t *testing.T) {
+	t.Run("SymmetricKey", func(t *testing.T) {
+		server := newTestServer(t, nil)
+
+		request := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+		response, err := server.router.Test(request)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, response.StatusCode)
+
+		keySet := requireBodyJWKSet(t, response.Body)
+		require.Empty(t, keySet.Keys)
+	})
+
+	t.Run("AsymmetricKey", func(t *testing.T) {
+		server := newTestServer(t, nil)
+
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		require.NoError(t, err)
+
+		maker, err := token.NewAsymmetricJWTMaker(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+		require.NoError(t, err)
+		server.tokenMaker = maker
+
+		request := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+		response, err := server.router.Test(request)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, response.StatusCode)
+
+		keySet := requireBodyJWKSet(t, response.Body)
+		require.Len(t, keySet.Keys, 1)
+		require.Equal(t, maker.SigningKeyID(), keySet.Keys[0].Kid)
+		require.Equal(t, "EdDSA", keySet.Keys[0].Alg)
+	})
+}
+
+func requireBodyJWKSet(t *testing.T, body io.Reader) token.JWKSet {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var keySet token.JWKSet
+	err = json.Unmarshal(data, &keySet)
+	require.NoError(t, err)
+	return keySet
+}
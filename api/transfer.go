@@ -1,50 +1,54 @@
 package api
 
 import (
+	"crypto/sha256"
 	"database/sql"
-	"errors"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"simple_bank/apierror"
 	db "simple_bank/db/sqlc"
+	"simple_bank/fx"
 	"simple_bank/token"
+	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
 )
 
 type transferRequest struct {
 	FromAccountID int64  `json:"from_account_id" validate:"required,min=1"`
 	ToAccountID   int64  `json:"to_account_id" validate:"required,min=1"`
 	Amount        int64  `json:"amount" validate:"required,gt=0"`
-	Currency      string `json:"currency" validate:"required,oneof=KRW USD EUR"`
+	Currency      string `json:"currency" validate:"required,currency"`
+	QuoteID       string `json:"quote_id" validate:"omitempty,uuid"`
 }
 
 func (server *Server) createTransfer(ctx *fiber.Ctx) error {
 	req := new(transferRequest)
 	// var req transferRequest
-	if err := ctx.BodyParser(req); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
-	}
-
-	validate := validator.New()
-	if err := validate.Struct(req); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
 	}
-	fromAccount, valid := server.validateAccount(ctx, req.FromAccountID, req.Currency)
-	if !valid {
-		err := errors.New("invalid from_account currency")
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+	fromAccount, err := server.validateAccount(ctx, req.FromAccountID, req.Currency)
+	if err != nil {
+		return err
 	}
 
 	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
 
-	if authPayload.Username == fromAccount.Owner {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(errors.New("fromAccount doesn't belongs to the authenticated user")))
+	if authPayload.Username != fromAccount.Owner {
+		return errAccountNotOwned
 	}
 
-	_, valid = server.validateAccount(ctx, req.ToAccountID, req.Currency)
-	if !valid {
-		err := errors.New("invalid to_account currency")
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+	toAccount, err := server.store.GetAccount(ctx.Context(), req.ToAccountID)
+	if err != nil {
+		return apierror.Wrap(fiber.StatusBadRequest, apierror.CodeAccountNotFound, "invalid to_account", err)
 	}
 
 	arg := db.TransferTxParams{
@@ -53,31 +57,137 @@ func (server *Server) createTransfer(ctx *fiber.Ctx) error {
 		Amount:        req.Amount,
 	}
 
-	result, err := server.store.TransferTx(ctx.Context(), arg)
+	idempotencyKey := ctx.Get(idempotencyKeyHeader)
+
+	// the amount is debited in the request currency and credited in the destination account's own currency
+	var exchange *db.TransferExchange
+	switch {
+	case len(req.QuoteID) > 0:
+		if toAccount.Currency == fromAccount.Currency {
+			return apierror.New(fiber.StatusBadRequest, apierror.CodeFxQuoteMismatch, "fx quotes only apply to cross-currency transfers")
+		}
+
+		quote, err := server.store.GetFxQuote(ctx.Context(), uuid.MustParse(req.QuoteID))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return apierror.New(fiber.StatusNotFound, apierror.CodeFxQuoteNotFound, "fx quote not found")
+			}
+			return err
+		}
+
+		exchange, err = quotedTransferExchange(quote, authPayload.Username, fromAccount, toAccount, req.Amount)
+		if err != nil {
+			return err
+		}
+
+		// a retry finds the quote used by its first attempt, so with an idempotency key the transaction
+		// replays the stored result first and only then refuses a used or expired quote
+		if len(idempotencyKey) == 0 && !quoteAvailable(quote) {
+			return db.ErrFxQuoteUnavailable
+		}
+	case toAccount.Currency != fromAccount.Currency:
+		exchange, err = fx.NewTransferExchange(ctx.Context(), server.rateProvider, fromAccount.Currency, toAccount.Currency, req.Amount)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(idempotencyKey) == 0 {
+		var result db.TransferTxResult
+		if exchange != nil {
+			result, err = server.store.CrossCurrencyTransferTx(ctx.Context(), db.CrossCurrencyTransferTxParams{
+				TransferTxParams: arg,
+				Exchange:         *exchange,
+			})
+		} else {
+			result, err = server.store.TransferTx(ctx.Context(), arg)
+		}
+		if err != nil {
+			return err
+		}
+
+		return ctx.JSON(result)
+	}
+
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		apiErr := apierror.New(fiber.StatusBadRequest, apierror.CodeValidationFailed, "request validation failed")
+		apiErr.Details = []apierror.FieldError{{
+			Field:  idempotencyKeyHeader,
+			Reason: fmt.Sprintf("must be at most %d characters", maxIdempotencyKeyLength),
+		}}
+		return apiErr
+	}
+
+	requestHash, err := hashRequest(req)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return err
+	}
+
+	result, err := server.store.IdempotentTransferTx(ctx.Context(), db.IdempotentTransferTxParams{
+		TransferTxParams: arg,
+		Exchange:         exchange,
+		Key:              idempotencyKey,
+		Username:         authPayload.Username,
+		RequestHash:      requestHash,
+		ExpiresAt:        time.Now().Add(server.config.IdempotencyKeyDuration),
+	})
+	if err != nil {
+		return err
 	}
 
 	return ctx.JSON(result)
 }
 
-func (server *Server) validateAccount(ctx *fiber.Ctx, accountID int64, currency string) (db.Account, bool) {
-	account, err := server.store.GetAccount(ctx.Context(), accountID)
+// hashRequest fingerprints a request so a reused idempotency key can be told apart from a replay
+func hashRequest(req interface{}) (string, error) {
+	data, err := json.Marshal(req)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			// ctx.JSON()
-			return account, false
-		}
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
 
-		return account, false
+// validateAccount loads the account a transfer is sent from and checks it is in the transfer currency
+func (server *Server) validateAccount(ctx *fiber.Ctx, accountID int64, currency string) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx.Context(), accountID)
+	if err != nil {
+		return account, apierror.Wrap(fiber.StatusBadRequest, apierror.CodeAccountNotFound, "invalid from_account", err)
 	}
 
 	if account.Currency != currency {
-		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, currency)
-		if err != nil {
-			return account, false
+		return account, currencyMismatch(account, currency)
+	}
+
+	return account, nil
+}
+
+type reverseTransferRequest struct {
+	TransferID int64 `params:"id" validate:"required,min=1"`
+	// Amount defaults to everything not reversed yet
+	Amount int64 `json:"amount" validate:"omitempty,gt=0"`
+}
+
+// reverseTransfer gives back a mistaken transfer, fully or in part
+func (server *Server) reverseTransfer(ctx *fiber.Ctx) error {
+	req := new(reverseTransferRequest)
+
+	// the body is optional
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	result, err := server.store.ReverseTransferTx(ctx.Context(), db.ReverseTransferTxParams{
+		TransferID: req.TransferID,
+		Amount:     req.Amount,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return apierror.New(fiber.StatusNotFound, apierror.CodeTransferNotFound, "transfer not found")
 		}
+		return err
 	}
 
-	return account, true
+	return ctx.JSON(result)
 }
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"database/sql"
+	db "simple_bank/db/sqlc"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type transferLimitRequest struct {
+	Currency  string `json:"currency" validate:"required,currency"`
+	Period    string `json:"period" validate:"required,oneof=day month"`
+	MaxCount  *int64 `json:"max_count" validate:"required_without=MaxAmount,omitempty,min=0"`
+	MaxAmount *int64 `json:"max_amount" validate:"required_without=MaxCount,omitempty,min=0"`
+}
+
+type setUserTransferLimitRequest struct {
+	Username string `params:"username" validate:"required"`
+	transferLimitRequest
+}
+
+func (server *Server) setUserTransferLimit(ctx *fiber.Ctx) error {
+	req := new(setUserTransferLimitRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	user, err := server.store.GetUser(ctx.Context(), req.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errUserNotFound
+		}
+		return err
+	}
+
+	limit, err := server.store.UpsertUserTransferLimit(ctx.Context(), db.UpsertUserTransferLimitParams{
+		Username:  &user.Username,
+		Currency:  req.Currency,
+		Period:    req.Period,
+		MaxCount:  req.MaxCount,
+		MaxAmount: req.MaxAmount,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(limit)
+}
+
+type listUserTransferLimitsRequest struct {
+	Username string `params:"username" validate:"required"`
+}
+
+func (server *Server) listUserTransferLimits(ctx *fiber.Ctx) error {
+	req := new(listUserTransferLimitsRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	limits, err := server.store.ListUserTransferLimits(ctx.Context(), req.Username)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(limits)
+}
+
+type setAccountTransferLimitRequest struct {
+	AccountID int64 `params:"id" validate:"required,min=1"`
+	transferLimitRequest
+}
+
+func (server *Server) setAccountTransferLimit(ctx *fiber.Ctx) error {
+	req := new(setAccountTransferLimitRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	account, err := server.store.GetAccount(ctx.Context(), req.AccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errAccountNotFound
+		}
+		return err
+	}
+
+	// transfers are limited in the currency they are sent in, so any other currency would never apply
+	if account.Currency != req.Currency {
+		return currencyMismatch(account, req.Currency)
+	}
+
+	limit, err := server.store.UpsertAccountTransferLimit(ctx.Context(), db.UpsertAccountTransferLimitParams{
+		AccountID: &account.ID,
+		Currency:  req.Currency,
+		Period:    req.Period,
+		MaxCount:  req.MaxCount,
+		MaxAmount: req.MaxAmount,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(limit)
+}
+
+type listAccountTransferLimitsRequest struct {
+	AccountID int64 `params:"id" validate:"required,min=1"`
+}
+
+func (server *Server) listAccountTransferLimits(ctx *fiber.Ctx) error {
+	req := new(listAccountTransferLimitsRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	limits, err := server.store.ListAccountTransferLimits(ctx.Context(), req.AccountID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(limits)
+}
+
+type deleteTransferLimitRequest struct {
+	ID int64 `params:"id" validate:"required,min=1"`
+}
+
+func (server *Server) deleteTransferLimit(ctx *fiber.Ctx) error {
+	req := new(deleteTransferLimitRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	if err := server.store.DeleteTransferLimit(ctx.Context(), req.ID); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
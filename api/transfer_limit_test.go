@@ -0,0 +1,282 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple_bank/db/mock"
+	db "simple_bank/db/sqlc"
+	"simple_bank/util"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetUserTransferLimitAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	maxAmount := int64(1000)
+
+	limit := db.TransferLimit{
+		ID:        util.RandomInt(1, 1000),
+		Username:  &user.Username,
+		Currency:  "USD",
+		Period:    db.LimitPeriodDay,
+		MaxAmount: &maxAmount,
+	}
+
+	testCases := []struct {
+		name          string
+		body          fiber.Map
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, response *http.Response)
+	}{
+		{
+			name: "OK",
+			body: fiber.Map{"currency": "USD", "period": "day", "max_amount": maxAmount},
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertUserTransferLimitParams{
+					Username:  &user.Username,
+					Currency:  "USD",
+					Period:    db.LimitPeriodDay,
+					MaxAmount: &maxAmount,
+				}
+
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().UpsertUserTransferLimit(gomock.Any(), gomock.Eq(arg)).Times(1).Return(limit, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+
+				data, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+
+				var gotLimit db.TransferLimit
+				err = json.Unmarshal(data, &gotLimit)
+				require.NoError(t, err)
+				require.Equal(t, limit, gotLimit)
+			},
+		},
+		{
+			name: "Customer",
+			body: fiber.Map{"currency": "USD", "period": "day", "max_amount": maxAmount},
+			role: util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertUserTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusForbidden, response.StatusCode)
+			},
+		},
+		{
+			name: "NoMaximum",
+			body: fiber.Map{"currency": "USD", "period": "day"},
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name: "NegativeMaxCount",
+			body: fiber.Map{"currency": "USD", "period": "day", "max_count": -1},
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name: "InvalidPeriod",
+			body: fiber.Map{"currency": "USD", "period": "week", "max_count": 3},
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name: "UserNotFound",
+			body: fiber.Map{"currency": "USD", "period": "month", "max_count": 3},
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().UpsertUserTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusNotFound, response.StatusCode)
+			},
+		},
+		{
+			name: "InternalError",
+			body: fiber.Map{"currency": "USD", "period": "month", "max_count": 3},
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().UpsertUserTransferLimit(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferLimit{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, response.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/users/%s/transfer_limits", user.Username)
+			request := httptest.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", tc.role, time.Minute)
+
+			response, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, response)
+		})
+	}
+}
+
+func TestSetAccountTransferLimitAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Currency = "EUR"
+	maxCount := int64(5)
+
+	testCases := []struct {
+		name          string
+		body          fiber.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, response *http.Response)
+	}{
+		{
+			name: "OK",
+			body: fiber.Map{"currency": "EUR", "period": "month", "max_count": maxCount},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertAccountTransferLimitParams{
+					AccountID: &account.ID,
+					Currency:  "EUR",
+					Period:    db.LimitPeriodMonth,
+					MaxCount:  &maxCount,
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					UpsertAccountTransferLimit(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferLimit{AccountID: &account.ID, Currency: "EUR", Period: db.LimitPeriodMonth, MaxCount: &maxCount}, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			body: fiber.Map{"currency": "USD", "period": "month", "max_count": maxCount},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpsertAccountTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name: "AccountNotFound",
+			body: fiber.Map{"currency": "EUR", "period": "month", "max_count": maxCount},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusNotFound, response.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/transfer_limits", account.ID)
+			request := httptest.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+
+			response, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, response)
+		})
+	}
+}
+
+func TestListAndDeleteTransferLimitsAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	username := util.RandomOwner()
+	accountID := util.RandomInt(1, 1000)
+	limits := []db.TransferLimit{{ID: 1, Username: &username, Currency: "USD", Period: db.LimitPeriodDay}}
+
+	store.EXPECT().ListUserTransferLimits(gomock.Any(), gomock.Eq(username)).Times(1).Return(limits, nil)
+	store.EXPECT().ListAccountTransferLimits(gomock.Any(), gomock.Eq(accountID)).Times(1).Return([]db.TransferLimit{}, sql.ErrConnDone)
+	store.EXPECT().DeleteTransferLimit(gomock.Any(), gomock.Eq(int64(1))).Times(1).Return(nil)
+
+	testCases := []struct {
+		method string
+		url    string
+		status int
+	}{
+		{method: http.MethodGet, url: fmt.Sprintf("/users/%s/transfer_limits", username), status: http.StatusOK},
+		{method: http.MethodGet, url: fmt.Sprintf("/accounts/%d/transfer_limits", accountID), status: http.StatusInternalServerError},
+		{method: http.MethodGet, url: "/accounts/invalid/transfer_limits", status: http.StatusBadRequest},
+		{method: http.MethodDelete, url: "/transfer_limits/1", status: http.StatusNoContent},
+	}
+
+	for _, tc := range testCases {
+		request := httptest.NewRequest(tc.method, tc.url, nil)
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+
+		response, err := server.router.Test(request)
+		require.NoError(t, err)
+		require.Equal(t, tc.status, response.StatusCode, tc.url)
+	}
+}
@@ -2,27 +2,28 @@ package api
 
 import (
 	"database/sql"
-	"log"
+	"simple_bank/apierror"
 	db "simple_bank/db/sqlc"
+	"simple_bank/token"
 	"simple_bank/util"
 	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
-	"github.com/lib/pq"
+	"github.com/google/uuid"
 )
 
 type createUserRequest struct {
-	Username string `json:"user_name" binding:"required,alphanum"`
-	Password string `json:"password" binding:"required,min=6"`
-	FullName string `json:"full_name" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
+	Username string `json:"user_name" validate:"required,alphanum"`
+	Password string `json:"password" validate:"required,min=6"`
+	FullName string `json:"full_name" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
 }
 
 type userResponse struct {
 	Username          string    `json:"username"`
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
+	Role              string    `json:"role"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreateAt          time.Time `json:"create_at"`
 }
@@ -32,6 +33,7 @@ func newUserResponse(user db.User) userResponse {
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
+		Role:              user.Role,
 		CreateAt:          user.CreateAt,
 		PasswordChangedAt: user.PasswordChangedAt,
 	}
@@ -41,18 +43,13 @@ func (server *Server) createUser(ctx *fiber.Ctx) error {
 
 	req := new(createUserRequest)
 
-	if err := ctx.BodyParser(req); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
-	}
-
-	validate := validator.New()
-	if err := validate.Struct(req); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
 	}
 
 	hashedPassword, err := util.HashPassword(req.Password)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return err
 	}
 
 	arg := db.CreateUserParams{
@@ -62,16 +59,9 @@ func (server *Server) createUser(ctx *fiber.Ctx) error {
 		Email:          req.Email,
 	}
 
-	user, err := server.store.CreateUser(ctx.Context(), arg)
+	user, err := server.store.CreateUserTx(ctx.Context(), arg)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			log.Println(pqErr.Code.Name())
-			switch pqErr.Code.Name() {
-			case "unique_violation":
-				return ctx.Status(fiber.StatusForbidden).JSON(errorResponse(err))
-			}
-		}
-		return ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return err
 	}
 
 	rsp := newUserResponse(user)
@@ -80,44 +70,146 @@ func (server *Server) createUser(ctx *fiber.Ctx) error {
 }
 
 type loginUserRequest struct {
-	Username string `json:"user_name" binding:"required,alphanum"`
-	Password string `json:"password" binding:"required,min=6"`
+	Username string `json:"user_name" validate:"required,alphanum"`
+	Password string `json:"password" validate:"required,min=6"`
 }
 
 type loginUserResponse struct {
-	AccessToken string       `json:"access_token"`
-	User        userResponse `json:"user"`
+	SessionID             uuid.UUID    `json:"session_id"`
+	AccessToken           string       `json:"access_token"`
+	AccessTokenExpiresAt  time.Time    `json:"access_token_expires_at"`
+	RefreshToken          string       `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time    `json:"refresh_token_expires_at"`
+	User                  userResponse `json:"user"`
 }
 
 func (server *Server) loginUser(ctx *fiber.Ctx) error {
 	req := new(loginUserRequest)
 
-	if err := ctx.BodyParser(req); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(errorResponse(err))
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
 	}
 
 	user, err := server.store.GetUser(ctx.Context(), req.Username)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return ctx.Status(fiber.StatusNotFound).JSON(errorResponse(err))
+			return errUserNotFound
 		}
-		return ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return err
 	}
 
 	err = util.CheckPassword(req.Password, user.HashedPassword)
 	if err != nil {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(errorResponse(err))
+		return apierror.Wrap(fiber.StatusUnauthorized, apierror.CodeIncorrectPassword, "incorrect password", err)
 	}
 
-	accessToken, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration)
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, token.TypeAccess, server.config.AccessTokenDuration)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(errorResponse(err))
+		return err
+	}
+
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, token.TypeRefresh, server.config.RefreshTokenDuration)
+	if err != nil {
+		return err
+	}
+
+	session, err := server.store.CreateSession(ctx.Context(), db.CreateSessionParams{
+		ID:           refreshPayload.ID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    ctx.Get(fiber.HeaderUserAgent),
+		ClientIp:     ctx.IP(),
+		IsBlocked:    false,
+		ExpiresAt:    refreshPayload.ExpiredAt,
+	})
+	if err != nil {
+		return err
 	}
 
 	rsp := loginUserResponse{
-		AccessToken: accessToken,
-		User:        newUserResponse(user),
+		SessionID:             session.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
+		User:                  newUserResponse(user),
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(rsp)
 }
+
+type logoutUserRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// logoutUser revokes the access token of the request. When a refresh token is given as well,
+// its session is blocked so it can no longer be used to renew access tokens.
+func (server *Server) logoutUser(ctx *fiber.Ctx) error {
+	req := new(logoutUserRequest)
+
+	// the body is optional
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
+
+	if len(req.RefreshToken) > 0 {
+		refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken)
+		if err != nil {
+			return apierror.Wrap(fiber.StatusUnauthorized, apierror.CodeInvalidToken, "refresh token is invalid", err)
+		}
+
+		if err := refreshPayload.CheckType(token.TypeRefresh); err != nil {
+			return apierror.Wrap(fiber.StatusUnauthorized, apierror.CodeInvalidToken, "refresh token is invalid", err)
+		}
+
+		if refreshPayload.Username != authPayload.Username {
+			return apierror.New(fiber.StatusUnauthorized, apierror.CodeResourceNotOwned, "refresh token doesn't belong to the authenticated user")
+		}
+
+		_, err = server.store.BlockSession(ctx.Context(), refreshPayload.ID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return apierror.New(fiber.StatusNotFound, apierror.CodeSessionNotFound, "session not found")
+			}
+			return err
+		}
+
+		if err := server.revocations.Revoke(ctx.Context(), refreshPayload); err != nil {
+			return err
+		}
+	}
+
+	if err := server.revocations.Revoke(ctx.Context(), authPayload); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+type updateUserRoleRequest struct {
+	Username string `params:"username" validate:"required"`
+	Role     string `json:"role" validate:"required,oneof=customer teller admin"`
+}
+
+func (server *Server) updateUserRole(ctx *fiber.Ctx) error {
+	req := new(updateUserRoleRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	user, err := server.store.UpdateUserRole(ctx.Context(), db.UpdateUserRoleParams{
+		Username: req.Username,
+		Role:     req.Role,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errUserNotFound
+		}
+		return err
+	}
+
+	return ctx.JSON(newUserResponse(user))
+}
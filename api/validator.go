@@ -0,0 +1,44 @@
+package api
+
+import (
+	"reflect"
+	"simple_bank/currency"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validCurrency checks the field holds one of the configured currencies
+func validCurrency(currencies *currency.Registry) validator.Func {
+	return func(fieldLevel validator.FieldLevel) bool {
+		if code, ok := fieldLevel.Field().Interface().(string); ok {
+			return currencies.IsSupported(code)
+		}
+		return false
+	}
+}
+
+// newValidator returns the validator shared by all handlers. Fields are reported by the name clients
+// send them under, taken from their params, query or json tag.
+func newValidator(currencies *currency.Registry) *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(requestFieldName)
+
+	if err := validate.RegisterValidation("currency", validCurrency(currencies)); err != nil {
+		panic(err)
+	}
+	return validate
+}
+
+func requestFieldName(field reflect.StructField) string {
+	for _, key := range []string{"params", "query", "json"} {
+		name := strings.SplitN(field.Tag.Get(key), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if len(name) > 0 {
+			return name
+		}
+	}
+	return field.Name
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"database/sql"
+	"simple_bank/apierror"
+	db "simple_bank/db/sqlc"
+	"simple_bank/token"
+	"simple_bank/webhook"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type createWebhookRequest struct {
+	URL        string   `json:"url" validate:"required,url"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=transfer.created account.created account.balance_updated"`
+}
+
+type webhookResponse struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newWebhookResponse(subscription db.WebhookSubscription) webhookResponse {
+	return webhookResponse{
+		ID:         subscription.ID,
+		URL:        subscription.Url,
+		EventTypes: subscription.EventTypes,
+		CreatedAt:  subscription.CreatedAt,
+	}
+}
+
+// createWebhookResponse is the only response that includes the signing secret
+type createWebhookResponse struct {
+	webhookResponse
+	Secret string `json:"secret"`
+}
+
+func (server *Server) createWebhook(ctx *fiber.Ctx) error {
+	req := new(createWebhookRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return err
+	}
+
+	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
+
+	subscription, err := server.store.CreateWebhookSubscription(ctx.Context(), db.CreateWebhookSubscriptionParams{
+		Username:   authPayload.Username,
+		Url:        req.URL,
+		Secret:     secret,
+		EventTypes: req.EventTypes,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(createWebhookResponse{
+		webhookResponse: newWebhookResponse(subscription),
+		Secret:          subscription.Secret,
+	})
+}
+
+func (server *Server) listWebhooks(ctx *fiber.Ctx) error {
+	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
+
+	subscriptions, err := server.store.ListWebhookSubscriptions(ctx.Context(), authPayload.Username)
+	if err != nil {
+		return err
+	}
+
+	rsp := make([]webhookResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		rsp = append(rsp, newWebhookResponse(subscription))
+	}
+
+	return ctx.JSON(rsp)
+}
+
+// authorizedWebhook loads the subscription and checks the authenticated user owns it
+func (server *Server) authorizedWebhook(ctx *fiber.Ctx, id int64) (db.WebhookSubscription, error) {
+	subscription, err := server.store.GetWebhookSubscription(ctx.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.WebhookSubscription{}, errWebhookNotFound
+		}
+		return db.WebhookSubscription{}, err
+	}
+
+	authPayload := ctx.Locals(authorizationPayloadKey).(*token.Payload)
+
+	if authPayload.Username != subscription.Username {
+		return db.WebhookSubscription{}, apierror.New(fiber.StatusUnauthorized, apierror.CodeResourceNotOwned, "webhook doesn't belong to the authenticated user")
+	}
+
+	return subscription, nil
+}
+
+type deleteWebhookRequest struct {
+	ID int64 `params:"id" validate:"required,min=1"`
+}
+
+// deleteWebhook removes the subscription along with its delivery log
+func (server *Server) deleteWebhook(ctx *fiber.Ctx) error {
+	req := new(deleteWebhookRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	subscription, err := server.authorizedWebhook(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := server.store.DeleteWebhookSubscription(ctx.Context(), subscription.ID); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+type listWebhookDeliveriesRequest struct {
+	ID       int64 `params:"id" validate:"required,min=1"`
+	PageID   int32 `query:"page_id" validate:"required,number,min=1"`
+	PageSize int32 `query:"page_size" validate:"required,number,min=5,max=10"`
+}
+
+// listWebhookDeliveries is the delivery log of a subscription, newest first
+func (server *Server) listWebhookDeliveries(ctx *fiber.Ctx) error {
+	req := new(listWebhookDeliveriesRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	subscription, err := server.authorizedWebhook(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	deliveries, err := server.store.ListWebhookDeliveries(ctx.Context(), db.ListWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Limit:          req.PageSize,
+		Offset:         (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(deliveries)
+}
+
+type replayWebhookDeliveryRequest struct {
+	ID         int64 `params:"id" validate:"required,min=1"`
+	DeliveryID int64 `params:"delivery_id" validate:"required,min=1"`
+}
+
+// replayWebhookDelivery queues a delivery to be sent again, with a fresh set of attempts
+func (server *Server) replayWebhookDelivery(ctx *fiber.Ctx) error {
+	req := new(replayWebhookDeliveryRequest)
+
+	if err := server.parseRequest(ctx, req); err != nil {
+		return err
+	}
+
+	subscription, err := server.authorizedWebhook(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	delivery, err := server.store.GetWebhookDelivery(ctx.Context(), req.DeliveryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errWebhookDeliveryNotFound
+		}
+		return err
+	}
+
+	if delivery.SubscriptionID != subscription.ID {
+		return errWebhookDeliveryNotFound
+	}
+
+	delivery, err = server.store.ReplayWebhookDelivery(ctx.Context(), delivery.ID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(delivery)
+}
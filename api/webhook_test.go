@@ -0,0 +1,310 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple_bank/db/mock"
+	db "simple_bank/db/sqlc"
+	"simple_bank/util"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func randomWebhookSubscription(username string) db.WebhookSubscription {
+	return db.WebhookSubscription{
+		ID:         util.RandomInt(1, 1000),
+		Username:   username,
+		Url:        "https://example.com/hooks",
+		Secret:     "whsec_" + util.RandomString(32),
+		EventTypes: []string{db.EventTransferCreated},
+	}
+}
+
+func TestCreateWebhookAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	subscription := randomWebhookSubscription(user.Username)
+
+	testCases := []struct {
+		name          string
+		body          fiber.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, response *http.Response)
+	}{
+		{
+			name: "OK",
+			body: fiber.Map{
+				"url":         subscription.Url,
+				"event_types": subscription.EventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookSubscription(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateWebhookSubscriptionParams) (db.WebhookSubscription, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, subscription.Url, arg.Url)
+						require.Equal(t, subscription.EventTypes, arg.EventTypes)
+						require.NotEmpty(t, arg.Secret)
+						return subscription, nil
+					})
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+
+				data, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+
+				var got createWebhookResponse
+				err = json.Unmarshal(data, &got)
+				require.NoError(t, err)
+				require.Equal(t, subscription.ID, got.ID)
+				require.Equal(t, subscription.Secret, got.Secret)
+			},
+		},
+		{
+			name: "UnsupportedEventType",
+			body: fiber.Map{
+				"url":         subscription.Url,
+				"event_types": []string{"user.created"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name: "NoEventTypes",
+			body: fiber.Map{
+				"url":         subscription.Url,
+				"event_types": []string{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name: "InvalidURL",
+			body: fiber.Map{
+				"url":         "not a url",
+				"event_types": subscription.EventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			},
+		},
+		{
+			name: "InternalError",
+			body: fiber.Map{
+				"url":         subscription.Url,
+				"event_types": subscription.EventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(1).Return(db.WebhookSubscription{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusInternalServerError, response.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(data))
+			request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.CustomerRole, time.Minute)
+
+			response, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, response)
+		})
+	}
+}
+
+func TestManageWebhookAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	subscription := randomWebhookSubscription(user.Username)
+	delivery := db.WebhookDelivery{
+		ID:             util.RandomInt(1, 1000),
+		SubscriptionID: subscription.ID,
+		EventID:        util.RandomInt(1, 1000),
+		Status:         db.WebhookDeliveryStatusFailed,
+		Attempts:       8,
+	}
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, response *http.Response)
+	}{
+		{
+			name:     "List",
+			method:   http.MethodGet,
+			url:      "/webhooks",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListWebhookSubscriptions(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return([]db.WebhookSubscription{subscription}, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+
+				data, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+				require.NotContains(t, string(data), subscription.Secret)
+			},
+		},
+		{
+			name:     "Delete",
+			method:   http.MethodDelete,
+			url:      fmt.Sprintf("/webhooks/%d", subscription.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+				store.EXPECT().DeleteWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusNoContent, response.StatusCode)
+			},
+		},
+		{
+			name:     "DeleteUnauthorizedUser",
+			method:   http.MethodDelete,
+			url:      fmt.Sprintf("/webhooks/%d", subscription.ID),
+			username: "unauthorized_user",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+				store.EXPECT().DeleteWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			},
+		},
+		{
+			name:     "DeleteNotFound",
+			method:   http.MethodDelete,
+			url:      fmt.Sprintf("/webhooks/%d", subscription.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(db.WebhookSubscription{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusNotFound, response.StatusCode)
+			},
+		},
+		{
+			name:     "ListDeliveries",
+			method:   http.MethodGet,
+			url:      fmt.Sprintf("/webhooks/%d/deliveries?page_id=1&page_size=5", subscription.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListWebhookDeliveriesParams{
+					SubscriptionID: subscription.ID,
+					Limit:          5,
+					Offset:         0,
+				}
+
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+				store.EXPECT().ListWebhookDeliveries(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.WebhookDelivery{delivery}, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+			},
+		},
+		{
+			name:     "Replay",
+			method:   http.MethodPost,
+			url:      fmt.Sprintf("/webhooks/%d/deliveries/%d/replay", subscription.ID, delivery.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				replayed := delivery
+				replayed.Status = db.WebhookDeliveryStatusPending
+				replayed.Attempts = 0
+
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(delivery, nil)
+				store.EXPECT().ReplayWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(replayed, nil)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusOK, response.StatusCode)
+
+				data, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+
+				var got db.WebhookDelivery
+				err = json.Unmarshal(data, &got)
+				require.NoError(t, err)
+				require.Equal(t, db.WebhookDeliveryStatusPending, got.Status)
+			},
+		},
+		{
+			name:     "ReplayOtherSubscriptionsDelivery",
+			method:   http.MethodPost,
+			url:      fmt.Sprintf("/webhooks/%d/deliveries/%d/replay", subscription.ID, delivery.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				other := delivery
+				other.SubscriptionID = subscription.ID + 1
+
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(other, nil)
+				store.EXPECT().ReplayWebhookDelivery(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, response *http.Response) {
+				require.Equal(t, http.StatusNotFound, response.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			request := httptest.NewRequest(tc.method, tc.url, nil)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.CustomerRole, time.Minute)
+
+			response, err := server.router.Test(request)
+			require.NoError(t, err)
+			tc.checkResponse(t, response)
+		})
+	}
+}
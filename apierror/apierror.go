@@ -0,0 +1,226 @@
+package apierror
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+)
+
+// Code identifies an error independently of its message, so clients can branch on it
+type Code string
+
+const (
+	CodeInvalidRequest    Code = "INVALID_REQUEST"
+	CodeValidationFailed  Code = "VALIDATION_FAILED"
+	CodeUnauthorized      Code = "UNAUTHORIZED"
+	CodeInvalidToken      Code = "INVALID_TOKEN"
+	CodeTokenRevoked      Code = "TOKEN_REVOKED"
+	CodeSessionBlocked    Code = "SESSION_BLOCKED"
+	CodeIncorrectPassword Code = "INCORRECT_PASSWORD"
+	CodeForbidden         Code = "FORBIDDEN"
+	CodeNotFound          Code = "NOT_FOUND"
+	CodeNotAcceptable     Code = "NOT_ACCEPTABLE"
+	CodeAlreadyExists     Code = "ALREADY_EXISTS"
+	CodeInvalidReference  Code = "INVALID_REFERENCE"
+	CodeConflict          Code = "CONFLICT"
+	CodeInternal          Code = "INTERNAL"
+
+	CodeUserNotFound              Code = "USER_NOT_FOUND"
+	CodeSessionNotFound           Code = "SESSION_NOT_FOUND"
+	CodeAccountNotFound           Code = "ACCOUNT_NOT_FOUND"
+	CodeAccountNotOwned           Code = "ACCOUNT_NOT_OWNED"
+	CodeResourceNotOwned          Code = "RESOURCE_NOT_OWNED"
+	CodeCurrencyMismatch          Code = "CURRENCY_MISMATCH"
+	CodeInsufficientFunds         Code = "INSUFFICIENT_FUNDS"
+	CodeTransferNotFound          Code = "TRANSFER_NOT_FOUND"
+	CodeTransferLimitExceeded     Code = "TRANSFER_LIMIT_EXCEEDED"
+	CodeTransferLimitNotFound     Code = "TRANSFER_LIMIT_NOT_FOUND"
+	CodeTransferNotReversible     Code = "TRANSFER_NOT_REVERSIBLE"
+	CodeTransferAlreadyReversed   Code = "TRANSFER_ALREADY_REVERSED"
+	CodeInvalidReversalAmount     Code = "INVALID_REVERSAL_AMOUNT"
+	CodeIdempotencyKeyMismatch    Code = "IDEMPOTENCY_KEY_MISMATCH"
+	CodeIdempotencyKeyConflict    Code = "IDEMPOTENCY_KEY_CONFLICT"
+	CodeFxRateNotFound            Code = "FX_RATE_NOT_FOUND"
+	CodeFxQuoteNotFound           Code = "FX_QUOTE_NOT_FOUND"
+	CodeFxQuoteUnavailable        Code = "FX_QUOTE_UNAVAILABLE"
+	CodeFxQuoteMismatch           Code = "FX_QUOTE_MISMATCH"
+	CodeFxAmountTooSmall          Code = "FX_AMOUNT_TOO_SMALL"
+	CodeInvalidPeriod             Code = "INVALID_PERIOD"
+	CodeHoldNotFound              Code = "HOLD_NOT_FOUND"
+	CodeHoldNotActive             Code = "HOLD_NOT_ACTIVE"
+	CodeInvalidCaptureAmount      Code = "INVALID_CAPTURE_AMOUNT"
+	CodeInvalidSchedule           Code = "INVALID_SCHEDULE"
+	CodeScheduledTransferNotFound Code = "SCHEDULED_TRANSFER_NOT_FOUND"
+	CodeScheduledTransferInactive Code = "SCHEDULED_TRANSFER_NOT_ACTIVE"
+	CodeWebhookNotFound           Code = "WEBHOOK_NOT_FOUND"
+	CodeWebhookDeliveryNotFound   Code = "WEBHOOK_DELIVERY_NOT_FOUND"
+)
+
+// FieldError describes why a single request field was rejected
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// Error is the body of every error response. Only Code, Message and Details are meant for clients;
+// the underlying cause is kept for logging.
+type Error struct {
+	Status    int          `json:"-"`
+	Code      Code         `json:"code"`
+	Message   string       `json:"message"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	cause     error
+}
+
+func New(status int, code Code, message string) *Error {
+	return &Error{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func Newf(status int, code Code, format string, args ...interface{}) *Error {
+	return New(status, code, fmt.Sprintf(format, args...))
+}
+
+// Wrap is like New but keeps err as the cause
+func Wrap(status int, code Code, message string, err error) *Error {
+	apiErr := New(status, code, message)
+	apiErr.cause = err
+	return apiErr
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.cause)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// InvalidBody rejects a request body that could not be parsed
+func InvalidBody(err error) *Error {
+	return Wrap(http.StatusBadRequest, CodeInvalidRequest, "request body is malformed", err)
+}
+
+// InvalidQuery rejects a query string that could not be parsed
+func InvalidQuery(err error) *Error {
+	return Wrap(http.StatusBadRequest, CodeInvalidRequest, "query string is malformed", err)
+}
+
+// InvalidParam rejects a path parameter that could not be parsed
+func InvalidParam(name string, err error) *Error {
+	apiErr := Wrap(http.StatusBadRequest, CodeValidationFailed, "request validation failed", err)
+	apiErr.Details = []FieldError{{Field: name, Reason: "is malformed"}}
+	return apiErr
+}
+
+// From maps err to an Error. Errors that are not recognized become an internal error whose message
+// doesn't reveal the cause.
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		apiErr = Wrap(http.StatusBadRequest, CodeValidationFailed, "request validation failed", err)
+		for _, fieldErr := range validationErrs {
+			apiErr.Details = append(apiErr.Details, FieldError{
+				Field:  fieldErr.Field(),
+				Reason: reason(fieldErr),
+			})
+		}
+		return apiErr
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return Wrap(http.StatusNotFound, CodeNotFound, "resource not found", err)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Name() {
+		case "unique_violation":
+			return Wrap(http.StatusForbidden, CodeAlreadyExists, "resource already exists", err)
+		case "foreign_key_violation":
+			return Wrap(http.StatusForbidden, CodeInvalidReference, "referenced resource does not exist", err)
+		}
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return Wrap(fiberErr.Code, codeForStatus(fiberErr.Code), fiberErr.Message, err)
+	}
+
+	return Wrap(http.StatusInternalServerError, CodeInternal, "internal server error", err)
+}
+
+func codeForStatus(status int) Code {
+	switch status {
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeInvalidRequest
+}
+
+// reason turns a failed validation tag into a short sentence about the field
+func reason(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+
+	switch fieldErr.Tag() {
+	case "required", "required_without":
+		return "is required"
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s]", param)
+	case "min", "gte":
+		return fmt.Sprintf("must be at least %s", param)
+	case "max", "lte":
+		return fmt.Sprintf("must be at most %s", param)
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", param)
+	case "lt":
+		return fmt.Sprintf("must be less than %s", param)
+	case "ne":
+		return fmt.Sprintf("must not be %s", param)
+	case "nefield":
+		return fmt.Sprintf("must differ from %s", param)
+	case "gtefield":
+		return fmt.Sprintf("must be at least %s", param)
+	case "email":
+		return "must be an email address"
+	case "url":
+		return "must be a URL"
+	case "uuid":
+		return "must be a UUID"
+	case "currency":
+		return "must be a supported currency"
+	case "alphanum":
+		return "must contain only letters and digits"
+	case "number":
+		return "must be a number"
+	case "datetime":
+		return fmt.Sprintf("must be a date formatted as %s", param)
+	}
+	return fmt.Sprintf("failed the %s check", fieldErr.Tag())
+}
@@ -0,0 +1,74 @@
+package apierror
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrom(t *testing.T) {
+	notFound := New(http.StatusNotFound, CodeAccountNotFound, "account not found")
+
+	testCases := []struct {
+		name   string
+		err    error
+		status int
+		code   Code
+	}{
+		{"APIError", notFound, http.StatusNotFound, CodeAccountNotFound},
+		{"WrappedAPIError", fmt.Errorf("get account: %w", notFound), http.StatusNotFound, CodeAccountNotFound},
+		{"NoRows", sql.ErrNoRows, http.StatusNotFound, CodeNotFound},
+		{"UniqueViolation", &pq.Error{Code: "23505"}, http.StatusForbidden, CodeAlreadyExists},
+		{"ForeignKeyViolation", &pq.Error{Code: "23503"}, http.StatusForbidden, CodeInvalidReference},
+		{"FiberError", fiber.ErrMethodNotAllowed, http.StatusMethodNotAllowed, CodeInvalidRequest},
+		{"FiberNotFound", fiber.ErrNotFound, http.StatusNotFound, CodeNotFound},
+		{"Unknown", sql.ErrConnDone, http.StatusInternalServerError, CodeInternal},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			apiErr := From(tc.err)
+			require.Equal(t, tc.status, apiErr.Status)
+			require.Equal(t, tc.code, apiErr.Code)
+			require.NotEmpty(t, apiErr.Message)
+		})
+	}
+}
+
+func TestFromInternalHidesCause(t *testing.T) {
+	cause := errors.New("pq: password authentication failed")
+
+	apiErr := From(cause)
+	require.Equal(t, CodeInternal, apiErr.Code)
+	require.NotContains(t, apiErr.Message, cause.Error())
+	require.ErrorIs(t, apiErr, cause)
+}
+
+func TestFromValidationErrors(t *testing.T) {
+	req := struct {
+		Currency string `validate:"required,oneof=KRW USD EUR"`
+		Amount   int64  `validate:"gt=0"`
+	}{
+		Currency: "GBP",
+	}
+
+	err := validator.New().Struct(req)
+	require.Error(t, err)
+
+	apiErr := From(err)
+	require.Equal(t, http.StatusBadRequest, apiErr.Status)
+	require.Equal(t, CodeValidationFailed, apiErr.Code)
+	require.Equal(t, []FieldError{
+		{Field: "Currency", Reason: "must be one of [KRW USD EUR]"},
+		{Field: "Amount", Reason: "must be greater than 0"},
+	}, apiErr.Details)
+}
@@ -0,0 +1,57 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	ErrUnknownCurrency     = errors.New("unknown currency")
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+)
+
+// Currency is the ISO 4217 metadata of a currency
+type Currency struct {
+	Code    string `json:"code"`
+	Numeric string `json:"numeric"`
+	// MinorUnits is the number of decimal places of the currency. Amounts are stored in minor units,
+	// so 1050 is $10.50 but ₩1,050.
+	MinorUnits int    `json:"minor_units"`
+	Symbol     string `json:"symbol"`
+}
+
+// iso4217 lists the currencies the bank knows about. Which of them are supported is configured.
+var iso4217 = map[string]Currency{
+	"AUD": {Code: "AUD", Numeric: "036", MinorUnits: 2, Symbol: "A$"},
+	"CAD": {Code: "CAD", Numeric: "124", MinorUnits: 2, Symbol: "CA$"},
+	"CHF": {Code: "CHF", Numeric: "756", MinorUnits: 2, Symbol: "CHF"},
+	"CNY": {Code: "CNY", Numeric: "156", MinorUnits: 2, Symbol: "CN¥"},
+	"EUR": {Code: "EUR", Numeric: "978", MinorUnits: 2, Symbol: "€"},
+	"GBP": {Code: "GBP", Numeric: "826", MinorUnits: 2, Symbol: "£"},
+	"HKD": {Code: "HKD", Numeric: "344", MinorUnits: 2, Symbol: "HK$"},
+	"JPY": {Code: "JPY", Numeric: "392", MinorUnits: 0, Symbol: "¥"},
+	"KRW": {Code: "KRW", Numeric: "410", MinorUnits: 0, Symbol: "₩"},
+	"KWD": {Code: "KWD", Numeric: "414", MinorUnits: 3, Symbol: "KD"},
+	"SGD": {Code: "SGD", Numeric: "702", MinorUnits: 2, Symbol: "S$"},
+	"USD": {Code: "USD", Numeric: "840", MinorUnits: 2, Symbol: "$"},
+}
+
+// Lookup returns the metadata of any ISO 4217 currency the bank knows about, supported or not
+func Lookup(code string) (Currency, error) {
+	currency, ok := iso4217[code]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %s", ErrUnknownCurrency, code)
+	}
+	return currency, nil
+}
+
+// MinorUnitScale returns 10^(to.MinorUnits - from.MinorUnits). An amount in minor units of from, times
+// a rate quoted in major units, times the scale is in minor units of to.
+func MinorUnitScale(from Currency, to Currency) *big.Rat {
+	exp := to.MinorUnits - from.MinorUnits
+	if exp < 0 {
+		return new(big.Rat).Inv(MinorUnitScale(to, from))
+	}
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+}
@@ -0,0 +1,110 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrOverflow         = errors.New("amount overflows")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
+
+// Money is an amount in the minor units of its currency
+type Money struct {
+	Amount   int64
+	Currency Currency
+}
+
+func New(amount int64, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency.Code != other.Currency.Code {
+		return Money{}, fmt.Errorf("%w: %s vs %s", ErrCurrencyMismatch, m.Currency.Code, other.Currency.Code)
+	}
+
+	a, b := m.Amount, other.Amount
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return Money{}, ErrOverflow
+	}
+	return New(a+b, m.Currency), nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	negated, err := other.Neg()
+	if err != nil {
+		return Money{}, err
+	}
+	return m.Add(negated)
+}
+
+func (m Money) Neg() (Money, error) {
+	if m.Amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return New(-m.Amount, m.Currency), nil
+}
+
+// Mul multiplies the amount by n, such as the number of runs of a scheduled transfer
+func (m Money) Mul(n int64) (Money, error) {
+	a := m.Amount
+	if a == 0 || n == 0 {
+		return New(0, m.Currency), nil
+	}
+
+	product := a * n
+	if product/n != a || (a == -1 && n == math.MinInt64) || (n == -1 && a == math.MinInt64) {
+		return Money{}, ErrOverflow
+	}
+	return New(product, m.Currency), nil
+}
+
+// Decimal returns the amount in major units, such as 1234.50 for 123450 USD cents
+func (m Money) Decimal() string {
+	sign, major, minor := m.split()
+	if m.Currency.MinorUnits == 0 {
+		return sign + major
+	}
+	return sign + major + "." + minor
+}
+
+// String formats the amount for display, such as $1,234.50 or -₩1,000
+func (m Money) String() string {
+	sign, major, minor := m.split()
+
+	var b strings.Builder
+	b.WriteString(sign)
+	b.WriteString(m.Currency.Symbol)
+	for i, digit := range major {
+		if i > 0 && (len(major)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	if m.Currency.MinorUnits > 0 {
+		b.WriteByte('.')
+		b.WriteString(minor)
+	}
+	return b.String()
+}
+
+// split returns the sign and the digits of the amount before and after the decimal point
+func (m Money) split() (sign, major, minor string) {
+	// formatted as unsigned so that math.MinInt64 doesn't overflow when negated
+	digits := strconv.FormatUint(uint64(m.Amount), 10)
+	if m.Amount < 0 {
+		sign = "-"
+		digits = strconv.FormatUint(-uint64(m.Amount), 10)
+	}
+
+	units := m.Currency.MinorUnits
+	if len(digits) <= units {
+		digits = strings.Repeat("0", units-len(digits)+1) + digits
+	}
+	return sign, digits[:len(digits)-units], digits[len(digits)-units:]
+}
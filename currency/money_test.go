@@ -0,0 +1,85 @@
+package currency
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoneyArithmetic(t *testing.T) {
+	usd, err := Lookup("USD")
+	require.NoError(t, err)
+	eur, err := Lookup("EUR")
+	require.NoError(t, err)
+
+	sum, err := New(1050, usd).Add(New(250, usd))
+	require.NoError(t, err)
+	require.Equal(t, New(1300, usd), sum)
+
+	difference, err := New(1050, usd).Sub(New(2000, usd))
+	require.NoError(t, err)
+	require.Equal(t, New(-950, usd), difference)
+
+	product, err := New(-1050, usd).Mul(3)
+	require.NoError(t, err)
+	require.Equal(t, New(-3150, usd), product)
+
+	_, err = New(1050, usd).Add(New(250, eur))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestMoneyOverflow(t *testing.T) {
+	usd, err := Lookup("USD")
+	require.NoError(t, err)
+
+	_, err = New(math.MaxInt64, usd).Add(New(1, usd))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(math.MinInt64, usd).Add(New(-1, usd))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(0, usd).Sub(New(math.MinInt64, usd))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(math.MinInt64, usd).Neg()
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(math.MaxInt64/2+1, usd).Mul(2)
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(math.MinInt64, usd).Mul(-1)
+	require.ErrorIs(t, err, ErrOverflow)
+
+	max, err := New(math.MaxInt64-1, usd).Add(New(1, usd))
+	require.NoError(t, err)
+	require.Equal(t, int64(math.MaxInt64), max.Amount)
+}
+
+func TestMoneyFormat(t *testing.T) {
+	testCases := []struct {
+		code    string
+		amount  int64
+		decimal string
+		display string
+	}{
+		{"USD", 123450, "1234.50", "$1,234.50"},
+		{"USD", 5, "0.05", "$0.05"},
+		{"USD", -5, "-0.05", "-$0.05"},
+		{"USD", 0, "0.00", "$0.00"},
+		{"EUR", 100000000, "1000000.00", "€1,000,000.00"},
+		{"KRW", 1050, "1050", "₩1,050"},
+		{"KRW", -999, "-999", "-₩999"},
+		{"KWD", 1234, "1.234", "KD1.234"},
+		{"USD", math.MinInt64, "-92233720368547758.08", "-$92,233,720,368,547,758.08"},
+	}
+
+	for _, tc := range testCases {
+		currency, err := Lookup(tc.code)
+		require.NoError(t, err)
+
+		money := New(tc.amount, currency)
+		require.Equal(t, tc.decimal, money.Decimal())
+		require.Equal(t, tc.display, money.String())
+	}
+}
@@ -0,0 +1,59 @@
+package currency
+
+import "fmt"
+
+// DefaultCodes are supported when no currencies are configured
+var DefaultCodes = []string{"KRW", "USD", "EUR"}
+
+// Registry holds the currencies accounts can be opened and moved in
+type Registry struct {
+	currencies map[string]Currency
+	codes      []string
+}
+
+// NewRegistry supports the currencies with the given codes, or DefaultCodes when there are none
+func NewRegistry(codes []string) (*Registry, error) {
+	if len(codes) == 0 {
+		codes = DefaultCodes
+	}
+
+	registry := &Registry{
+		currencies: make(map[string]Currency, len(codes)),
+	}
+	for _, code := range codes {
+		currency, err := Lookup(code)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := registry.currencies[code]; ok {
+			return nil, fmt.Errorf("currency %s is listed twice", code)
+		}
+
+		registry.currencies[code] = currency
+		registry.codes = append(registry.codes, code)
+	}
+
+	return registry, nil
+}
+
+func (registry *Registry) Get(code string) (Currency, error) {
+	currency, ok := registry.currencies[code]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, code)
+	}
+	return currency, nil
+}
+
+func (registry *Registry) IsSupported(code string) bool {
+	_, ok := registry.currencies[code]
+	return ok
+}
+
+// Currencies returns the supported currencies in the order they were configured
+func (registry *Registry) Currencies() []Currency {
+	currencies := make([]Currency, 0, len(registry.codes))
+	for _, code := range registry.codes {
+		currencies = append(currencies, registry.currencies[code])
+	}
+	return currencies
+}
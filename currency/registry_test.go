@@ -0,0 +1,59 @@
+package currency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	usd, err := Lookup("USD")
+	require.NoError(t, err)
+	require.Equal(t, Currency{Code: "USD", Numeric: "840", MinorUnits: 2, Symbol: "$"}, usd)
+
+	krw, err := Lookup("KRW")
+	require.NoError(t, err)
+	require.Zero(t, krw.MinorUnits)
+
+	_, err = Lookup("XYZ")
+	require.ErrorIs(t, err, ErrUnknownCurrency)
+}
+
+func TestRegistry(t *testing.T) {
+	registry, err := NewRegistry([]string{"USD", "JPY"})
+	require.NoError(t, err)
+
+	require.True(t, registry.IsSupported("JPY"))
+	require.False(t, registry.IsSupported("KRW"))
+
+	jpy, err := registry.Get("JPY")
+	require.NoError(t, err)
+	require.Equal(t, "392", jpy.Numeric)
+
+	// known to ISO 4217 but not configured
+	_, err = registry.Get("KRW")
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+
+	currencies := registry.Currencies()
+	require.Len(t, currencies, 2)
+	require.Equal(t, "USD", currencies[0].Code)
+	require.Equal(t, "JPY", currencies[1].Code)
+}
+
+func TestRegistryDefaults(t *testing.T) {
+	registry, err := NewRegistry(nil)
+	require.NoError(t, err)
+
+	for _, code := range DefaultCodes {
+		require.True(t, registry.IsSupported(code))
+	}
+	require.Len(t, registry.Currencies(), len(DefaultCodes))
+}
+
+func TestRegistryInvalidCodes(t *testing.T) {
+	_, err := NewRegistry([]string{"USD", "XYZ"})
+	require.ErrorIs(t, err, ErrUnknownCurrency)
+
+	_, err = NewRegistry([]string{"USD", "USD"})
+	require.Error(t, err)
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "transfer_conversions";
+DROP TABLE IF EXISTS "exchange_rates";
@@ -0,0 +1,26 @@
+CREATE TABLE "exchange_rates" (
+  "id" bigserial PRIMARY KEY,
+  "base_currency" varchar NOT NULL,
+  "quote_currency" varchar NOT NULL,
+  "rate" numeric(20,10) NOT NULL,
+  "effective_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_conversions" (
+  "transfer_id" bigint PRIMARY KEY,
+  "from_currency" varchar NOT NULL,
+  "to_currency" varchar NOT NULL,
+  "rate" numeric(20,10) NOT NULL,
+  "from_amount" bigint NOT NULL,
+  "to_amount" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "exchange_rates" ("base_currency", "quote_currency", "effective_at");
+
+COMMENT ON COLUMN "exchange_rates"."rate" IS 'units of quote_currency per unit of base_currency';
+
+ALTER TABLE "exchange_rates" ADD CONSTRAINT "exchange_rates_rate_check" CHECK ("rate" > 0);
+
+ALTER TABLE "transfer_conversions" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
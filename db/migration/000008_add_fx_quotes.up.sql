@@ -0,0 +1,20 @@
+CREATE TABLE "fx_quotes" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_currency" varchar NOT NULL,
+  "to_currency" varchar NOT NULL,
+  "rate" numeric(20,10) NOT NULL,
+  "from_amount" bigint NOT NULL,
+  "to_amount" bigint NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "fx_quotes" ("username");
+
+ALTER TABLE "fx_quotes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_conversions" ADD COLUMN "quote_id" uuid UNIQUE;
+
+ALTER TABLE "transfer_conversions" ADD FOREIGN KEY ("quote_id") REFERENCES "fx_quotes" ("id");
@@ -0,0 +1,31 @@
+CREATE TABLE "transfer_limits" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar,
+  "account_id" bigint,
+  "currency" varchar NOT NULL,
+  "period" varchar NOT NULL,
+  "max_count" bigint,
+  "max_amount" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_limits" ADD CONSTRAINT "transfer_limits_owner_check" CHECK (num_nonnulls("username", "account_id") = 1);
+
+ALTER TABLE "transfer_limits" ADD CONSTRAINT "transfer_limits_period_check" CHECK ("period" IN ('day', 'month'));
+
+ALTER TABLE "transfer_limits" ADD CONSTRAINT "transfer_limits_max_check" CHECK ("max_count" >= 0 AND "max_amount" >= 0);
+
+CREATE UNIQUE INDEX ON "transfer_limits" ("username", "currency", "period");
+
+CREATE UNIQUE INDEX ON "transfer_limits" ("account_id", "currency", "period");
+
+CREATE INDEX ON "transfers" ("created_at");
+
+COMMENT ON COLUMN "transfer_limits"."max_count" IS 'null means no limit on the number of transfers';
+
+COMMENT ON COLUMN "transfer_limits"."max_amount" IS 'null means no limit on the total amount';
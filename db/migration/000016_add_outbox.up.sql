@@ -0,0 +1,15 @@
+CREATE TABLE "outbox_events" (
+  "id" bigserial PRIMARY KEY,
+  "aggregate_type" varchar NOT NULL,
+  "aggregate_id" varchar NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "published_at" timestamptz
+);
+
+CREATE INDEX ON "outbox_events" ("id") WHERE "published_at" IS NULL;
+
+COMMENT ON TABLE "outbox_events" IS 'domain events written in the same transaction as the change they describe';
+
+COMMENT ON COLUMN "outbox_events"."published_at" IS 'null until the relay has handed the event to the publisher';
@@ -1,9 +1,10 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
     account_id,
-    amount
+    amount,
+    transfer_id
 ) VALUES (
-    $1, $2
+    $1, $2, $3
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -16,3 +17,45 @@ WHERE account_id = $1
 ORDER BY id
 LIMIT $2
 OFFSET $3;
+
+-- name: GetEntriesTotal :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM entries
+WHERE account_id = $1
+  AND created_at < sqlc.arg(before);
+
+-- name: GetLedgerBalance :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS balance
+FROM entries
+WHERE account_id = $1;
+
+-- name: ListStatementEntries :many
+SELECT
+    e.id,
+    e.account_id,
+    e.amount,
+    e.transfer_id,
+    e.created_at,
+    t.from_account_id,
+    t.to_account_id
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE e.account_id = sqlc.arg(account_id)
+  AND e.created_at >= sqlc.arg(from_time)
+  AND e.created_at < sqlc.arg(to_time)
+ORDER BY e.created_at, e.id;
+
+-- name: ListAccountEntries :many
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND id < sqlc.arg(before_id)
+  AND created_at >= sqlc.arg(from_time)
+  AND created_at < sqlc.arg(to_time)
+  AND abs(amount) BETWEEN sqlc.arg(min_amount)::bigint AND sqlc.arg(max_amount)::bigint
+  AND (
+    sqlc.arg(direction)::text = ''
+    OR (sqlc.arg(direction)::text = 'in' AND amount > 0)
+    OR (sqlc.arg(direction)::text = 'out' AND amount < 0)
+  )
+ORDER BY id DESC
+LIMIT sqlc.arg(page_size);
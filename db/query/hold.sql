@@ -0,0 +1,56 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+    account_id,
+    amount,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: ListActiveHolds :many
+SELECT * FROM holds
+WHERE account_id = $1
+  AND status = 'active'
+  AND expires_at > now()
+ORDER BY id;
+
+-- name: GetHeldAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS held
+FROM holds
+WHERE account_id = $1
+  AND status = 'active'
+  AND expires_at > now();
+
+-- name: CaptureHold :one
+UPDATE holds
+SET status = 'captured',
+    updated_at = now()
+WHERE id = $1
+  AND status = 'active'
+  AND expires_at > now()
+RETURNING *;
+
+-- name: SetHoldTransfer :one
+UPDATE holds
+SET transfer_id = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: ReleaseHold :one
+UPDATE holds
+SET status = 'released',
+    updated_at = now()
+WHERE id = $1
+  AND status = 'active'
+RETURNING *;
+
+-- name: ExpireHolds :execrows
+UPDATE holds
+SET status = 'expired',
+    updated_at = now()
+WHERE status = 'active'
+  AND expires_at <= now();
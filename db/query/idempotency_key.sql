@@ -0,0 +1,19 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+    key,
+    username,
+    request_hash,
+    response,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetIdempotencyKeyForUpdate :one
+SELECT * FROM idempotency_keys
+WHERE username = $1 AND key = $2 LIMIT 1
+FOR UPDATE;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE username = $1 AND key = $2;
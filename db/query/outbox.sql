@@ -0,0 +1,27 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox_events (
+    aggregate_type,
+    aggregate_id,
+    event_type,
+    payload
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListUnpublishedOutboxEvents :many
+SELECT * FROM outbox_events
+WHERE published_at IS NULL
+ORDER BY id
+LIMIT $1;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox_events
+SET published_at = now()
+WHERE id = $1;
+
+-- name: LockOutboxRelay :one
+SELECT pg_try_advisory_xact_lock(sqlc.arg(lock_id)::bigint) AS locked;
+
+-- name: GetOutboxEvent :one
+SELECT * FROM outbox_events
+WHERE id = $1 LIMIT 1;
@@ -0,0 +1,17 @@
+-- name: CreateRevokedToken :exec
+INSERT INTO revoked_tokens (
+    id,
+    username,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) ON CONFLICT (id) DO NOTHING;
+
+-- name: ListActiveRevokedTokens :many
+SELECT * FROM revoked_tokens
+WHERE expires_at > now()
+ORDER BY expires_at;
+
+-- name: DeleteExpiredRevokedTokens :execrows
+DELETE FROM revoked_tokens
+WHERE expires_at <= now();
@@ -0,0 +1,86 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+    owner,
+    from_account_id,
+    to_account_id,
+    amount,
+    currency,
+    schedule,
+    next_run_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetScheduledTransferForUpdate :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListDueScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE status = 'active'
+  AND next_run_at <= sqlc.arg(now)::timestamptz
+ORDER BY next_run_at
+LIMIT sqlc.arg(page_size);
+
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET amount = $2,
+    schedule = $3,
+    next_run_at = $4,
+    updated_at = now()
+WHERE id = $1
+  AND status = 'active'
+RETURNING *;
+
+-- name: AdvanceScheduledTransfer :one
+UPDATE scheduled_transfers
+SET next_run_at = sqlc.arg(next_run_at),
+    status = CASE WHEN sqlc.arg(next_run_at)::timestamptz IS NULL THEN 'completed' ELSE status END,
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CancelScheduledTransfer :one
+UPDATE scheduled_transfers
+SET status = 'cancelled',
+    next_run_at = NULL,
+    updated_at = now()
+WHERE id = $1
+  AND status = 'active'
+RETURNING *;
+
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (
+    scheduled_transfer_id,
+    scheduled_for
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: FinishScheduledTransferRun :one
+UPDATE scheduled_transfer_runs
+SET status = $2,
+    transfer_id = $3,
+    failure_reason = $4,
+    finished_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ListScheduledTransferRuns :many
+SELECT * FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
@@ -2,15 +2,29 @@
 INSERT INTO transfers (
     from_account_id,
     to_account_id,
-    amount
+    amount,
+    reversal_of
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4
 ) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetReversalTotals :one
+SELECT
+    COALESCE(SUM(t.amount), 0)::bigint AS debited,
+    COALESCE(SUM(COALESCE(c.to_amount, t.amount)), 0)::bigint AS refunded
+FROM transfers t
+LEFT JOIN transfer_conversions c ON c.transfer_id = t.id
+WHERE t.reversal_of = sqlc.arg(transfer_id)::bigint;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
 WHERE
@@ -20,3 +34,26 @@ ORDER BY id
 LIMIT $3
 OFFSET $4;
 
+
+-- name: ListAccountTransfers :many
+-- The amount bounds are in the account's own currency: incoming cross-currency transfers
+-- are filtered on the amount they credited.
+SELECT t.* FROM transfers t
+LEFT JOIN transfer_conversions c ON c.transfer_id = t.id
+WHERE (
+    t.from_account_id = sqlc.arg(account_id)
+    OR t.to_account_id = sqlc.arg(account_id)
+  )
+  AND t.id < sqlc.arg(before_id)
+  AND t.created_at >= sqlc.arg(from_time)
+  AND t.created_at < sqlc.arg(to_time)
+  AND (
+    CASE WHEN t.to_account_id = sqlc.arg(account_id) THEN COALESCE(c.to_amount, t.amount) ELSE t.amount END
+  ) BETWEEN sqlc.arg(min_amount)::bigint AND sqlc.arg(max_amount)::bigint
+  AND (
+    sqlc.arg(direction)::text = ''
+    OR (sqlc.arg(direction)::text = 'in' AND t.to_account_id = sqlc.arg(account_id))
+    OR (sqlc.arg(direction)::text = 'out' AND t.from_account_id = sqlc.arg(account_id))
+  )
+ORDER BY t.id DESC
+LIMIT sqlc.arg(page_size);
@@ -0,0 +1,16 @@
+-- name: CreateTransferConversion :one
+INSERT INTO transfer_conversions (
+    transfer_id,
+    from_currency,
+    to_currency,
+    rate,
+    from_amount,
+    to_amount,
+    quote_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetTransferConversion :one
+SELECT * FROM transfer_conversions
+WHERE transfer_id = $1 LIMIT 1;
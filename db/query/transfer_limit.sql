@@ -0,0 +1,71 @@
+-- name: UpsertUserTransferLimit :one
+INSERT INTO transfer_limits (
+    username,
+    currency,
+    period,
+    max_count,
+    max_amount
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (username, currency, period) DO UPDATE
+SET max_count = EXCLUDED.max_count,
+    max_amount = EXCLUDED.max_amount,
+    updated_at = now()
+RETURNING *;
+
+-- name: UpsertAccountTransferLimit :one
+INSERT INTO transfer_limits (
+    account_id,
+    currency,
+    period,
+    max_count,
+    max_amount
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (account_id, currency, period) DO UPDATE
+SET max_count = EXCLUDED.max_count,
+    max_amount = EXCLUDED.max_amount,
+    updated_at = now()
+RETURNING *;
+
+-- name: ListUserTransferLimits :many
+SELECT * FROM transfer_limits
+WHERE username = sqlc.arg(username)::varchar
+ORDER BY id;
+
+-- name: ListAccountTransferLimits :many
+SELECT * FROM transfer_limits
+WHERE account_id = sqlc.arg(account_id)::bigint
+ORDER BY id;
+
+-- name: ListApplicableTransferLimits :many
+SELECT * FROM transfer_limits
+WHERE currency = sqlc.arg(currency)
+  AND (username = sqlc.arg(username)::varchar OR account_id = sqlc.arg(account_id)::bigint)
+ORDER BY id;
+
+-- name: DeleteTransferLimit :exec
+DELETE FROM transfer_limits
+WHERE id = $1;
+
+-- name: GetAccountOutgoingTotals :one
+SELECT
+    COUNT(*)::bigint AS count,
+    COALESCE(SUM(amount), 0)::bigint AS amount
+FROM transfers
+WHERE from_account_id = sqlc.arg(account_id)
+  AND reversal_of IS NULL
+  AND created_at >= sqlc.arg(since);
+
+-- name: GetUserOutgoingTotals :one
+SELECT
+    COUNT(*)::bigint AS count,
+    COALESCE(SUM(t.amount), 0)::bigint AS amount
+FROM transfers t
+JOIN accounts a ON a.id = t.from_account_id
+WHERE a.owner = sqlc.arg(owner)
+  AND a.currency = sqlc.arg(currency)
+  AND t.reversal_of IS NULL
+  AND t.created_at >= sqlc.arg(since);
@@ -11,3 +11,14 @@ INSERT INTO users (
 -- name: GetUser :one
 SELECT * FROM users
 WHERE username = $1 LIMIT 1;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE username = $1
+RETURNING *;
+
+-- name: LockUser :exec
+SELECT username FROM users
+WHERE username = $1
+FOR NO KEY UPDATE;
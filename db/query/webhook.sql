@@ -0,0 +1,79 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (
+    username,
+    url,
+    secret,
+    event_types
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE username = $1
+ORDER BY id;
+
+-- name: DeleteWebhookSubscription :exec
+DELETE FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: CreateWebhookDeliveries :execrows
+INSERT INTO webhook_deliveries (
+    subscription_id,
+    event_id
+)
+SELECT id, sqlc.arg(event_id)::bigint
+FROM webhook_subscriptions
+WHERE username = ANY(sqlc.arg(usernames)::varchar[])
+  AND sqlc.arg(event_type)::varchar = ANY(event_types)
+ON CONFLICT (subscription_id, event_id) DO NOTHING;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = now() + sqlc.arg(lease_seconds)::int * interval '1 second',
+    updated_at = now()
+WHERE id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending'
+      AND next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT sqlc.arg(page_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: RecordWebhookDeliveryAttempt :one
+UPDATE webhook_deliveries
+SET status = $2,
+    attempts = attempts + 1,
+    next_attempt_at = $3,
+    response_status = $4,
+    last_error = $5,
+    delivered_at = CASE WHEN $2 = 'succeeded' THEN now() ELSE delivered_at END,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ReplayWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status = 'pending',
+    attempts = 0,
+    next_attempt_at = now(),
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
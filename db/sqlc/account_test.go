@@ -14,8 +14,9 @@ func createRandomAccount(t *testing.T) Account {
 	user := createRandomUser(t)
 
 	arg := CreateAccountParams{
-		Owner:    user.Username,
-		Balance:  util.RandomMoney(),
+		Owner: user.Username,
+		// keep enough funds for the transfers and withdrawals of the tx tests
+		Balance:  util.RandomInt(1000, 10000),
 		Currency: util.RandomCurrency(),
 	}
 
@@ -101,3 +102,22 @@ func TestListAccounts(t *testing.T) {
 		require.Equal(t, lastAccount.Owner, account.Owner)
 	}
 }
+
+func TestUpdateAccountOverdraftLimit(t *testing.T) {
+	account1 := createRandomAccount(t)
+	require.Zero(t, account1.OverdraftLimit)
+
+	arg := UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: util.RandomMoney(),
+	}
+
+	account2, err := testQueries.UpdateAccountOverdraftLimit(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.OverdraftLimit, account2.OverdraftLimit)
+	require.Equal(t, account1.Balance, account2.Balance)
+
+	arg.OverdraftLimit = -1
+	_, err = testQueries.UpdateAccountOverdraftLimit(context.Background(), arg)
+	require.Error(t, err)
+}
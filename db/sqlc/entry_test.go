@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAccountEntries(t *testing.T) {
+	account := createRandomAccount(t)
+
+	var ids []int64
+	for _, amount := range []int64{10, -20, 30, -40} {
+		entry, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{
+			AccountID: account.ID,
+			Amount:    amount,
+		})
+		require.NoError(t, err)
+		ids = append(ids, entry.ID)
+	}
+
+	arg := ListAccountEntriesParams{
+		AccountID: account.ID,
+		BeforeID:  math.MaxInt64,
+		ToTime:    time.Now().Add(time.Minute),
+		MaxAmount: math.MaxInt64,
+		PageSize:  2,
+	}
+
+	entries, err := testQueries.ListAccountEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, ids[3], entries[0].ID)
+	require.Equal(t, ids[2], entries[1].ID)
+
+	arg.BeforeID = entries[1].ID
+	entries, err = testQueries.ListAccountEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, ids[1], entries[0].ID)
+	require.Equal(t, ids[0], entries[1].ID)
+
+	arg.BeforeID = math.MaxInt64
+	arg.PageSize = 10
+	arg.Direction = "out"
+	arg.MinAmount = 25
+	entries, err = testQueries.ListAccountEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, int64(-40), entries[0].Amount)
+}
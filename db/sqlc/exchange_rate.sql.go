@@ -0,0 +1,77 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: exchange_rate.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createExchangeRate = `-- name: CreateExchangeRate :one
+INSERT INTO exchange_rates (
+    base_currency,
+    quote_currency,
+    rate,
+    effective_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, base_currency, quote_currency, rate, effective_at, created_at
+`
+
+type CreateExchangeRateParams struct {
+	BaseCurrency  string    `json:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency"`
+	Rate          string    `json:"rate"`
+	EffectiveAt   time.Time `json:"effective_at"`
+}
+
+func (q *Queries) CreateExchangeRate(ctx context.Context, arg CreateExchangeRateParams) (ExchangeRate, error) {
+	row := q.db.QueryRowContext(ctx, createExchangeRate,
+		arg.BaseCurrency,
+		arg.QuoteCurrency,
+		arg.Rate,
+		arg.EffectiveAt,
+	)
+	var i ExchangeRate
+	err := row.Scan(
+		&i.ID,
+		&i.BaseCurrency,
+		&i.QuoteCurrency,
+		&i.Rate,
+		&i.EffectiveAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getExchangeRate = `-- name: GetExchangeRate :one
+SELECT id, base_currency, quote_currency, rate, effective_at, created_at FROM exchange_rates
+WHERE base_currency = $1
+  AND quote_currency = $2
+  AND effective_at <= $3
+ORDER BY effective_at DESC
+LIMIT 1
+`
+
+type GetExchangeRateParams struct {
+	BaseCurrency  string    `json:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency"`
+	At            time.Time `json:"at"`
+}
+
+func (q *Queries) GetExchangeRate(ctx context.Context, arg GetExchangeRateParams) (ExchangeRate, error) {
+	row := q.db.QueryRowContext(ctx, getExchangeRate, arg.BaseCurrency, arg.QuoteCurrency, arg.At)
+	var i ExchangeRate
+	err := row.Scan(
+		&i.ID,
+		&i.BaseCurrency,
+		&i.QuoteCurrency,
+		&i.Rate,
+		&i.EffectiveAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"simple_bank/util"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetExchangeRate(t *testing.T) {
+	// a random pair keeps the test independent from rates left by other runs
+	base := util.RandomString(3)
+	quote := util.RandomString(3)
+	now := time.Now()
+
+	for i, rate := range []string{"1.5", "2"} {
+		exchangeRate, err := testQueries.CreateExchangeRate(context.Background(), CreateExchangeRateParams{
+			BaseCurrency:  base,
+			QuoteCurrency: quote,
+			Rate:          rate,
+			EffectiveAt:   now.Add(time.Duration(i-1) * time.Hour),
+		})
+		require.NoError(t, err)
+		require.NotZero(t, exchangeRate.ID)
+	}
+
+	exchangeRate, err := testQueries.GetExchangeRate(context.Background(), GetExchangeRateParams{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		At:            now,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "2.0000000000", exchangeRate.Rate)
+
+	exchangeRate, err = testQueries.GetExchangeRate(context.Background(), GetExchangeRateParams{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		At:            now.Add(-30 * time.Minute),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "1.5000000000", exchangeRate.Rate)
+
+	_, err = testQueries.GetExchangeRate(context.Background(), GetExchangeRateParams{
+		BaseCurrency:  quote,
+		QuoteCurrency: base,
+		At:            now,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,116 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: fx_quote.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createFxQuote = `-- name: CreateFxQuote :one
+INSERT INTO fx_quotes (
+    id,
+    username,
+    from_currency,
+    to_currency,
+    rate,
+    from_amount,
+    to_amount,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, username, from_currency, to_currency, rate, from_amount, to_amount, expires_at, used_at, created_at
+`
+
+type CreateFxQuoteParams struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	FromCurrency string    `json:"from_currency"`
+	ToCurrency   string    `json:"to_currency"`
+	Rate         string    `json:"rate"`
+	FromAmount   int64     `json:"from_amount"`
+	ToAmount     int64     `json:"to_amount"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateFxQuote(ctx context.Context, arg CreateFxQuoteParams) (FxQuote, error) {
+	row := q.db.QueryRowContext(ctx, createFxQuote,
+		arg.ID,
+		arg.Username,
+		arg.FromCurrency,
+		arg.ToCurrency,
+		arg.Rate,
+		arg.FromAmount,
+		arg.ToAmount,
+		arg.ExpiresAt,
+	)
+	var i FxQuote
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.Rate,
+		&i.FromAmount,
+		&i.ToAmount,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getFxQuote = `-- name: GetFxQuote :one
+SELECT id, username, from_currency, to_currency, rate, from_amount, to_amount, expires_at, used_at, created_at FROM fx_quotes
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetFxQuote(ctx context.Context, id uuid.UUID) (FxQuote, error) {
+	row := q.db.QueryRowContext(ctx, getFxQuote, id)
+	var i FxQuote
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.Rate,
+		&i.FromAmount,
+		&i.ToAmount,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useFxQuote = `-- name: UseFxQuote :one
+UPDATE fx_quotes
+SET used_at = now()
+WHERE id = $1
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING id, username, from_currency, to_currency, rate, from_amount, to_amount, expires_at, used_at, created_at
+`
+
+func (q *Queries) UseFxQuote(ctx context.Context, id uuid.UUID) (FxQuote, error) {
+	row := q.db.QueryRowContext(ctx, useFxQuote, id)
+	var i FxQuote
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.Rate,
+		&i.FromAmount,
+		&i.ToAmount,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
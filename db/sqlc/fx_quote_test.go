@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomFxQuote(t *testing.T, expiresAt time.Time) FxQuote {
+	user := createRandomUser(t)
+
+	arg := CreateFxQuoteParams{
+		ID:           uuid.New(),
+		Username:     user.Username,
+		FromCurrency: "USD",
+		ToCurrency:   "EUR",
+		Rate:         "0.9",
+		FromAmount:   100,
+		ToAmount:     90,
+		ExpiresAt:    expiresAt,
+	}
+
+	quote, err := testQueries.CreateFxQuote(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.ID, quote.ID)
+	require.Equal(t, arg.Username, quote.Username)
+	require.Equal(t, "0.9000000000", quote.Rate)
+	require.Equal(t, arg.ToAmount, quote.ToAmount)
+	require.WithinDuration(t, arg.ExpiresAt, quote.ExpiresAt, time.Second)
+	require.Nil(t, quote.UsedAt)
+
+	return quote
+}
+
+func TestUseFxQuote(t *testing.T) {
+	quote := createRandomFxQuote(t, time.Now().Add(time.Minute))
+
+	used, err := testQueries.UseFxQuote(context.Background(), quote.ID)
+	require.NoError(t, err)
+	require.NotNil(t, used.UsedAt)
+
+	// a quote is honoured only once
+	_, err = testQueries.UseFxQuote(context.Background(), quote.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	got, err := testQueries.GetFxQuote(context.Background(), quote.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.UsedAt)
+}
+
+func TestUseExpiredFxQuote(t *testing.T) {
+	quote := createRandomFxQuote(t, time.Now().Add(-time.Minute))
+
+	_, err := testQueries.UseFxQuote(context.Background(), quote.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
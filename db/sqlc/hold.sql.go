@@ -0,0 +1,214 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: hold.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const captureHold = `-- name: CaptureHold :one
+UPDATE holds
+SET status = 'captured',
+    updated_at = now()
+WHERE id = $1
+  AND status = 'active'
+  AND expires_at > now()
+RETURNING id, account_id, amount, status, expires_at, transfer_id, created_at, updated_at
+`
+
+func (q *Queries) CaptureHold(ctx context.Context, id int64) (Hold, error) {
+	row := q.db.QueryRowContext(ctx, captureHold, id)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.TransferID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createHold = `-- name: CreateHold :one
+INSERT INTO holds (
+    account_id,
+    amount,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING id, account_id, amount, status, expires_at, transfer_id, created_at, updated_at
+`
+
+type CreateHoldParams struct {
+	AccountID int64     `json:"account_id"`
+	Amount    int64     `json:"amount"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error) {
+	row := q.db.QueryRowContext(ctx, createHold, arg.AccountID, arg.Amount, arg.ExpiresAt)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.TransferID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const expireHolds = `-- name: ExpireHolds :execrows
+UPDATE holds
+SET status = 'expired',
+    updated_at = now()
+WHERE status = 'active'
+  AND expires_at <= now()
+`
+
+func (q *Queries) ExpireHolds(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, expireHolds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getHeldAmount = `-- name: GetHeldAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS held
+FROM holds
+WHERE account_id = $1
+  AND status = 'active'
+  AND expires_at > now()
+`
+
+func (q *Queries) GetHeldAmount(ctx context.Context, accountID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getHeldAmount, accountID)
+	var held int64
+	err := row.Scan(&held)
+	return held, err
+}
+
+const getHold = `-- name: GetHold :one
+SELECT id, account_id, amount, status, expires_at, transfer_id, created_at, updated_at FROM holds
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetHold(ctx context.Context, id int64) (Hold, error) {
+	row := q.db.QueryRowContext(ctx, getHold, id)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.TransferID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listActiveHolds = `-- name: ListActiveHolds :many
+SELECT id, account_id, amount, status, expires_at, transfer_id, created_at, updated_at FROM holds
+WHERE account_id = $1
+  AND status = 'active'
+  AND expires_at > now()
+ORDER BY id
+`
+
+func (q *Queries) ListActiveHolds(ctx context.Context, accountID int64) ([]Hold, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveHolds, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Hold{}
+	for rows.Next() {
+		var i Hold
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.TransferID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const releaseHold = `-- name: ReleaseHold :one
+UPDATE holds
+SET status = 'released',
+    updated_at = now()
+WHERE id = $1
+  AND status = 'active'
+RETURNING id, account_id, amount, status, expires_at, transfer_id, created_at, updated_at
+`
+
+func (q *Queries) ReleaseHold(ctx context.Context, id int64) (Hold, error) {
+	row := q.db.QueryRowContext(ctx, releaseHold, id)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.TransferID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setHoldTransfer = `-- name: SetHoldTransfer :one
+UPDATE holds
+SET transfer_id = $2
+WHERE id = $1
+RETURNING id, account_id, amount, status, expires_at, transfer_id, created_at, updated_at
+`
+
+type SetHoldTransferParams struct {
+	ID         int64  `json:"id"`
+	TransferID *int64 `json:"transfer_id"`
+}
+
+func (q *Queries) SetHoldTransfer(ctx context.Context, arg SetHoldTransferParams) (Hold, error) {
+	row := q.db.QueryRowContext(ctx, setHoldTransfer, arg.ID, arg.TransferID)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.TransferID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
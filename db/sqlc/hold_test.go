@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	amount := account.Balance / 2
+
+	hold, err := store.PlaceHoldTx(context.Background(), PlaceHoldTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.NotZero(t, hold.ID)
+	require.Equal(t, HoldStatusActive, hold.Status)
+	require.Nil(t, hold.TransferID)
+
+	held, err := store.GetHeldAmount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, amount, held)
+
+	// the held amount is no longer available to withdraw
+	_, err = store.WithdrawTx(context.Background(), EntryTxParams{
+		AccountID: account.ID,
+		Amount:    account.Balance - amount + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.PlaceHoldTx(context.Background(), PlaceHoldTxParams{
+		AccountID: account.ID,
+		Amount:    account.Balance - amount + 1,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	holds, err := store.ListActiveHolds(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, holds, 1)
+	require.Equal(t, hold.ID, holds[0].ID)
+}
+
+func TestCaptureHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	amount := int64(100)
+
+	hold, err := store.PlaceHoldTx(context.Background(), PlaceHoldTxParams{
+		AccountID: account1.ID,
+		Amount:    amount,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID:      hold.ID,
+		ToAccountID: account2.ID,
+		Amount:      amount + 1,
+	})
+	require.ErrorIs(t, err, ErrInvalidCaptureAmount)
+
+	// capturing part of the hold gives the rest back
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID:      hold.ID,
+		ToAccountID: account2.ID,
+		Amount:      amount / 2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusCaptured, result.Hold.Status)
+	require.NotNil(t, result.Hold.TransferID)
+	require.Equal(t, result.Transfer.Transfer.ID, *result.Hold.TransferID)
+	require.Equal(t, amount/2, result.Transfer.Transfer.Amount)
+	require.Equal(t, account1.Balance-amount/2, result.Transfer.FromAccount.Balance)
+	require.Equal(t, account2.Balance+amount/2, result.Transfer.ToAccount.Balance)
+
+	held, err := store.GetHeldAmount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Zero(t, held)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID:      hold.ID,
+		ToAccountID: account2.ID,
+	})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestReleaseHold(t *testing.T) {
+	account := createRandomAccount(t)
+
+	hold, err := testQueries.CreateHold(context.Background(), CreateHoldParams{
+		AccountID: account.ID,
+		Amount:    10,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	released, err := testQueries.ReleaseHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusReleased, released.Status)
+
+	held, err := testQueries.GetHeldAmount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Zero(t, held)
+
+	_, err = testQueries.ReleaseHold(context.Background(), hold.ID)
+	require.Error(t, err)
+}
+
+func TestExpireHolds(t *testing.T) {
+	account := createRandomAccount(t)
+
+	hold, err := testQueries.CreateHold(context.Background(), CreateHoldParams{
+		AccountID: account.ID,
+		Amount:    10,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	// an expired hold stops counting even before the job gets to it
+	held, err := testQueries.GetHeldAmount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Zero(t, held)
+
+	count, err := testQueries.ExpireHolds(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, count, int64(1))
+
+	expired, err := testQueries.GetHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusExpired, expired.Status)
+}
@@ -0,0 +1,92 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: idempotency_key.sql
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const createIdempotencyKey = `-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+    key,
+    username,
+    request_hash,
+    response,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING key, username, request_hash, response, expires_at, created_at
+`
+
+type CreateIdempotencyKeyParams struct {
+	Key         string          `json:"key"`
+	Username    string          `json:"username"`
+	RequestHash string          `json:"request_hash"`
+	Response    json.RawMessage `json:"response"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+}
+
+func (q *Queries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRowContext(ctx, createIdempotencyKey,
+		arg.Key,
+		arg.Username,
+		arg.RequestHash,
+		arg.Response,
+		arg.ExpiresAt,
+	)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.Username,
+		&i.RequestHash,
+		&i.Response,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE username = $1 AND key = $2
+`
+
+type DeleteIdempotencyKeyParams struct {
+	Username string `json:"username"`
+	Key      string `json:"key"`
+}
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, deleteIdempotencyKey, arg.Username, arg.Key)
+	return err
+}
+
+const getIdempotencyKeyForUpdate = `-- name: GetIdempotencyKeyForUpdate :one
+SELECT key, username, request_hash, response, expires_at, created_at FROM idempotency_keys
+WHERE username = $1 AND key = $2 LIMIT 1
+FOR UPDATE
+`
+
+type GetIdempotencyKeyForUpdateParams struct {
+	Username string `json:"username"`
+	Key      string `json:"key"`
+}
+
+func (q *Queries) GetIdempotencyKeyForUpdate(ctx context.Context, arg GetIdempotencyKeyForUpdateParams) (IdempotencyKey, error) {
+	row := q.db.QueryRowContext(ctx, getIdempotencyKeyForUpdate, arg.Username, arg.Key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.Username,
+		&i.RequestHash,
+		&i.Response,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -5,7 +5,10 @@
 package db
 
 import (
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Account struct {
@@ -14,6 +17,8 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// how far below zero the balance may go
+	OverdraftLimit int64 `json:"overdraft_limit"`
 }
 
 type Entry struct {
@@ -22,6 +27,110 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// set when the entry is one side of a transfer
+	TransferID *int64 `json:"transfer_id"`
+}
+
+type ExchangeRate struct {
+	ID            int64  `json:"id"`
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	// units of quote_currency per unit of base_currency
+	Rate        string    `json:"rate"`
+	EffectiveAt time.Time `json:"effective_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type FxQuote struct {
+	ID           uuid.UUID  `json:"id"`
+	Username     string     `json:"username"`
+	FromCurrency string     `json:"from_currency"`
+	ToCurrency   string     `json:"to_currency"`
+	Rate         string     `json:"rate"`
+	FromAmount   int64      `json:"from_amount"`
+	ToAmount     int64      `json:"to_amount"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	UsedAt       *time.Time `json:"used_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type Hold struct {
+	ID        int64     `json:"id"`
+	AccountID int64     `json:"account_id"`
+	Amount    int64     `json:"amount"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// the transfer the hold was captured into
+	TransferID *int64    `json:"transfer_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type IdempotencyKey struct {
+	Key         string          `json:"key"`
+	Username    string          `json:"username"`
+	RequestHash string          `json:"request_hash"`
+	Response    json.RawMessage `json:"response"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// domain events written in the same transaction as the change they describe
+type OutboxEvent struct {
+	ID            int64           `json:"id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+	// null until the relay has handed the event to the publisher
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+type RevokedToken struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	// recurrence rule, empty for a one-off transfer
+	Schedule string `json:"schedule"`
+	Status   string `json:"status"`
+	// null once the transfer is completed or cancelled
+	NextRunAt *time.Time `json:"next_run_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64 `json:"id"`
+	ScheduledTransferID int64 `json:"scheduled_transfer_id"`
+	// a run is claimed at most once for each occurrence
+	ScheduledFor  time.Time  `json:"scheduled_for"`
+	Status        string     `json:"status"`
+	TransferID    *int64     `json:"transfer_id"`
+	FailureReason string     `json:"failure_reason"`
+	CreatedAt     time.Time  `json:"created_at"`
+	FinishedAt    *time.Time `json:"finished_at"`
+}
+
+type Session struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	RefreshToken string    `json:"refresh_token"`
+	UserAgent    string    `json:"user_agent"`
+	ClientIp     string    `json:"client_ip"`
+	IsBlocked    bool      `json:"is_blocked"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 type Transfer struct {
@@ -31,6 +140,33 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// the transfer this one gives money back for
+	ReversalOf *int64 `json:"reversal_of"`
+}
+
+type TransferConversion struct {
+	TransferID   int64      `json:"transfer_id"`
+	FromCurrency string     `json:"from_currency"`
+	ToCurrency   string     `json:"to_currency"`
+	Rate         string     `json:"rate"`
+	FromAmount   int64      `json:"from_amount"`
+	ToAmount     int64      `json:"to_amount"`
+	CreatedAt    time.Time  `json:"created_at"`
+	QuoteID      *uuid.UUID `json:"quote_id"`
+}
+
+type TransferLimit struct {
+	ID        int64   `json:"id"`
+	Username  *string `json:"username"`
+	AccountID *int64  `json:"account_id"`
+	Currency  string  `json:"currency"`
+	Period    string  `json:"period"`
+	// null means no limit on the number of transfers
+	MaxCount *int64 `json:"max_count"`
+	// null means no limit on the total amount
+	MaxAmount *int64    `json:"max_amount"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type User struct {
@@ -40,4 +176,30 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreateAt          time.Time `json:"create_at"`
+	Role              string    `json:"role"`
+}
+
+type WebhookDelivery struct {
+	ID             int64     `json:"id"`
+	SubscriptionID int64     `json:"subscription_id"`
+	EventID        int64     `json:"event_id"`
+	Status         string    `json:"status"`
+	Attempts       int32     `json:"attempts"`
+	NextAttemptAt  time.Time `json:"next_attempt_at"`
+	// http status of the last attempt, null if it got no response
+	ResponseStatus *int32     `json:"response_status"`
+	LastError      string     `json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type WebhookSubscription struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Url      string `json:"url"`
+	// signs the deliveries, so it is kept in the clear
+	Secret     string    `json:"secret"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
 }
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"simple_bank/util"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// relayAll publishes every pending event and returns them in the order they were published
+func relayAll(t *testing.T, store Store) []OutboxEvent {
+	var published []OutboxEvent
+
+	for {
+		n, err := store.RelayOutboxTx(context.Background(), RelayOutboxTxParams{
+			Limit: 100,
+			Publish: func(ctx context.Context, event OutboxEvent) error {
+				published = append(published, event)
+				return nil
+			},
+		})
+		require.NoError(t, err)
+
+		if n == 0 {
+			return published
+		}
+	}
+}
+
+func TestOutboxEvents(t *testing.T) {
+	store := NewStore(testDB)
+	relayAll(t, store)
+
+	user := createRandomUser(t)
+	account1, err := store.CreateAccountTx(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  100,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+	account2 := createRandomAccount(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	published := relayAll(t, store)
+	require.Len(t, published, 4)
+
+	require.Equal(t, EventAccountCreated, published[0].EventType)
+	require.Equal(t, strconv.FormatInt(account1.ID, 10), published[0].AggregateID)
+
+	require.Equal(t, EventTransferCreated, published[1].EventType)
+	require.Equal(t, AggregateTransfer, published[1].AggregateType)
+	require.Less(t, published[0].ID, published[1].ID)
+
+	var payload transferCreatedEvent
+	require.NoError(t, json.Unmarshal(published[1].Payload, &payload))
+	require.Equal(t, result.Transfer.ID, payload.ID)
+	require.Equal(t, "USD", payload.Currency)
+
+	var balance balanceUpdatedEvent
+	require.Equal(t, EventAccountBalanceUpdated, published[2].EventType)
+	require.NoError(t, json.Unmarshal(published[2].Payload, &balance))
+	require.Equal(t, account1.ID, balance.AccountID)
+	require.Equal(t, user.Username, balance.Owner)
+	require.Equal(t, int64(90), balance.Balance)
+	require.Equal(t, int64(-10), balance.Amount)
+	require.Equal(t, EventAccountBalanceUpdated, published[3].EventType)
+
+	// a failed transfer leaves no event behind
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1000,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	require.Empty(t, relayAll(t, store))
+}
+
+func TestRelayOutboxTxPublishError(t *testing.T) {
+	store := NewStore(testDB)
+	relayAll(t, store)
+
+	user, err := store.CreateUserTx(context.Background(), CreateUserParams{
+		Username:       util.RandomOwner(),
+		HashedPassword: "secret",
+		FullName:       util.RandomOwner(),
+		Email:          util.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	n, err := store.RelayOutboxTx(context.Background(), RelayOutboxTxParams{
+		Limit: 100,
+		Publish: func(ctx context.Context, event OutboxEvent) error {
+			return errors.New("downstream unavailable")
+		},
+	})
+	require.Error(t, err)
+	require.Zero(t, n)
+
+	// the event is still waiting for the next relay
+	published := relayAll(t, store)
+	require.Len(t, published, 1)
+	require.Equal(t, EventUserCreated, published[0].EventType)
+	require.Equal(t, user.Username, published[0].AggregateID)
+	require.NotContains(t, string(published[0].Payload), "secret")
+}
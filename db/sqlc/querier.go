@@ -0,0 +1,102 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Querier interface {
+	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AdvanceScheduledTransfer(ctx context.Context, arg AdvanceScheduledTransferParams) (ScheduledTransfer, error)
+	BlockSession(ctx context.Context, id uuid.UUID) (Session, error)
+	CancelScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	CaptureHold(ctx context.Context, id int64) (Hold, error)
+	ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateExchangeRate(ctx context.Context, arg CreateExchangeRateParams) (ExchangeRate, error)
+	CreateFxQuote(ctx context.Context, arg CreateFxQuoteParams) (FxQuote, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (OutboxEvent, error)
+	CreateRevokedToken(ctx context.Context, arg CreateRevokedTokenParams) error
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferConversion(ctx context.Context, arg CreateTransferConversionParams) (TransferConversion, error)
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateWebhookDeliveries(ctx context.Context, arg CreateWebhookDeliveriesParams) (int64, error)
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
+	DeleteAccount(ctx context.Context, id int64) error
+	DeleteExpiredRevokedTokens(ctx context.Context) (int64, error)
+	DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error
+	DeleteTransferLimit(ctx context.Context, id int64) error
+	DeleteWebhookSubscription(ctx context.Context, id int64) error
+	ExpireHolds(ctx context.Context) (int64, error)
+	FinishScheduledTransferRun(ctx context.Context, arg FinishScheduledTransferRunParams) (ScheduledTransferRun, error)
+	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountOutgoingTotals(ctx context.Context, arg GetAccountOutgoingTotalsParams) (GetAccountOutgoingTotalsRow, error)
+	GetAccounts(ctx context.Context, arg GetAccountsParams) ([]Account, error)
+	GetEntriesTotal(ctx context.Context, arg GetEntriesTotalParams) (int64, error)
+	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetExchangeRate(ctx context.Context, arg GetExchangeRateParams) (ExchangeRate, error)
+	GetFxQuote(ctx context.Context, id uuid.UUID) (FxQuote, error)
+	GetHeldAmount(ctx context.Context, accountID int64) (int64, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetIdempotencyKeyForUpdate(ctx context.Context, arg GetIdempotencyKeyForUpdateParams) (IdempotencyKey, error)
+	GetLedgerBalance(ctx context.Context, accountID int64) (int64, error)
+	GetOutboxEvent(ctx context.Context, id int64) (OutboxEvent, error)
+	GetReversalTotals(ctx context.Context, transferID int64) (GetReversalTotalsRow, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferConversion(ctx context.Context, transferID int64) (TransferConversion, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetUser(ctx context.Context, username string) (User, error)
+	GetUserOutgoingTotals(ctx context.Context, arg GetUserOutgoingTotalsParams) (GetUserOutgoingTotalsRow, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error)
+	ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]Entry, error)
+	ListAccountTransferLimits(ctx context.Context, accountID int64) ([]TransferLimit, error)
+	// The amount bounds are in the account's own currency: incoming cross-currency transfers
+	// are filtered on the amount they credited.
+	ListAccountTransfers(ctx context.Context, arg ListAccountTransfersParams) ([]Transfer, error)
+	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListActiveHolds(ctx context.Context, accountID int64) ([]Hold, error)
+	ListActiveRevokedTokens(ctx context.Context) ([]RevokedToken, error)
+	ListApplicableTransferLimits(ctx context.Context, arg ListApplicableTransferLimitsParams) ([]TransferLimit, error)
+	ListDueScheduledTransfers(ctx context.Context, arg ListDueScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error)
+	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListUnpublishedOutboxEvents(ctx context.Context, limit int32) ([]OutboxEvent, error)
+	ListUserTransferLimits(ctx context.Context, username string) ([]TransferLimit, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookSubscriptions(ctx context.Context, username string) ([]WebhookSubscription, error)
+	LockOutboxRelay(ctx context.Context, lockID int64) (bool, error)
+	LockUser(ctx context.Context, username string) error
+	MarkOutboxEventPublished(ctx context.Context, id int64) error
+	RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) (WebhookDelivery, error)
+	ReleaseHold(ctx context.Context, id int64) (Hold, error)
+	ReplayWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	SetHoldTransfer(ctx context.Context, arg SetHoldTransferParams) (Hold, error)
+	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error)
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpsertAccountTransferLimit(ctx context.Context, arg UpsertAccountTransferLimitParams) (TransferLimit, error)
+	UpsertUserTransferLimit(ctx context.Context, arg UpsertUserTransferLimitParams) (TransferLimit, error)
+	UseFxQuote(ctx context.Context, id uuid.UUID) (FxQuote, error)
+}
+
+var _ Querier = (*Queries)(nil)
@@ -0,0 +1,81 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: revoked_token.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createRevokedToken = `-- name: CreateRevokedToken :exec
+INSERT INTO revoked_tokens (
+    id,
+    username,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) ON CONFLICT (id) DO NOTHING
+`
+
+type CreateRevokedTokenParams struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateRevokedToken(ctx context.Context, arg CreateRevokedTokenParams) error {
+	_, err := q.db.ExecContext(ctx, createRevokedToken, arg.ID, arg.Username, arg.ExpiresAt)
+	return err
+}
+
+const deleteExpiredRevokedTokens = `-- name: DeleteExpiredRevokedTokens :execrows
+DELETE FROM revoked_tokens
+WHERE expires_at <= now()
+`
+
+func (q *Queries) DeleteExpiredRevokedTokens(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredRevokedTokens)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listActiveRevokedTokens = `-- name: ListActiveRevokedTokens :many
+SELECT id, username, expires_at, revoked_at FROM revoked_tokens
+WHERE expires_at > now()
+ORDER BY expires_at
+`
+
+func (q *Queries) ListActiveRevokedTokens(ctx context.Context) ([]RevokedToken, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveRevokedTokens)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RevokedToken{}
+	for rows.Next() {
+		var i RevokedToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.ExpiresAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func containsRevokedToken(tokens []RevokedToken, id uuid.UUID) bool {
+	for _, revokedToken := range tokens {
+		if revokedToken.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRevokedTokens(t *testing.T) {
+	user := createRandomUser(t)
+
+	active := CreateRevokedTokenParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	expired := CreateRevokedTokenParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(-time.Hour),
+	}
+
+	for _, arg := range []CreateRevokedTokenParams{active, expired} {
+		err := testQueries.CreateRevokedToken(context.Background(), arg)
+		require.NoError(t, err)
+	}
+
+	// revoking the same token twice is a no-op
+	err := testQueries.CreateRevokedToken(context.Background(), active)
+	require.NoError(t, err)
+
+	tokens, err := testQueries.ListActiveRevokedTokens(context.Background())
+	require.NoError(t, err)
+	require.True(t, containsRevokedToken(tokens, active.ID))
+	require.False(t, containsRevokedToken(tokens, expired.ID))
+
+	deleted, err := testQueries.DeleteExpiredRevokedTokens(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	tokens, err = testQueries.ListActiveRevokedTokens(context.Background())
+	require.NoError(t, err)
+	require.True(t, containsRevokedToken(tokens, active.ID))
+}
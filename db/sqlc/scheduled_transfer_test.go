@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomScheduledTransfer(t *testing.T, schedule string, nextRunAt time.Time) ScheduledTransfer {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	scheduled, err := testQueries.CreateScheduledTransfer(context.Background(), CreateScheduledTransferParams{
+		Owner:         account1.Owner,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      account1.Currency,
+		Schedule:      schedule,
+		NextRunAt:     &nextRunAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferStatusActive, scheduled.Status)
+
+	return scheduled
+}
+
+func TestClaimScheduledTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	scheduledFor := time.Now().Add(-time.Minute).UTC().Truncate(time.Microsecond)
+	nextRunAt := scheduledFor.AddDate(0, 1, 0)
+	scheduled := createRandomScheduledTransfer(t, "FREQ=MONTHLY", scheduledFor)
+
+	due, err := store.ListDueScheduledTransfers(context.Background(), ListDueScheduledTransfersParams{
+		Now:      time.Now(),
+		PageSize: 1000,
+	})
+	require.NoError(t, err)
+
+	var dueIDs []int64
+	for _, d := range due {
+		dueIDs = append(dueIDs, d.ID)
+	}
+	require.Contains(t, dueIDs, scheduled.ID)
+
+	run, err := store.ClaimScheduledTransferTx(context.Background(), ClaimScheduledTransferTxParams{
+		ID:           scheduled.ID,
+		ScheduledFor: scheduledFor,
+		NextRunAt:    &nextRunAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduledRunStatusPending, run.Status)
+	require.WithinDuration(t, scheduledFor, run.ScheduledFor, time.Microsecond)
+
+	// the same occurrence can't be claimed twice
+	_, err = store.ClaimScheduledTransferTx(context.Background(), ClaimScheduledTransferTxParams{
+		ID:           scheduled.ID,
+		ScheduledFor: scheduledFor,
+		NextRunAt:    &nextRunAt,
+	})
+	require.ErrorIs(t, err, ErrScheduledRunClaimed)
+
+	advanced, err := store.GetScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferStatusActive, advanced.Status)
+	require.WithinDuration(t, nextRunAt, *advanced.NextRunAt, time.Microsecond)
+
+	finished, err := store.FinishScheduledTransferRun(context.Background(), FinishScheduledTransferRunParams{
+		ID:            run.ID,
+		Status:        ScheduledRunStatusFailed,
+		TransferID:    nil,
+		FailureReason: ErrInsufficientFunds.Error(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduledRunStatusFailed, finished.Status)
+	require.Nil(t, finished.TransferID)
+	require.NotNil(t, finished.FinishedAt)
+
+	runs, err := store.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		Limit:               5,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+}
+
+func TestClaimLastScheduledTransferRun(t *testing.T) {
+	store := NewStore(testDB)
+
+	scheduledFor := time.Now().Add(-time.Minute).UTC().Truncate(time.Microsecond)
+	scheduled := createRandomScheduledTransfer(t, "", scheduledFor)
+
+	_, err := store.ClaimScheduledTransferTx(context.Background(), ClaimScheduledTransferTxParams{
+		ID:           scheduled.ID,
+		ScheduledFor: scheduledFor,
+	})
+	require.NoError(t, err)
+
+	completed, err := store.GetScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferStatusCompleted, completed.Status)
+	require.Nil(t, completed.NextRunAt)
+
+	_, err = store.CancelScheduledTransfer(context.Background(), scheduled.ID)
+	require.Error(t, err)
+}
+
+func TestCancelScheduledTransfer(t *testing.T) {
+	scheduled := createRandomScheduledTransfer(t, "FREQ=DAILY", time.Now().Add(time.Hour))
+
+	cancelled, err := testQueries.CancelScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferStatusCancelled, cancelled.Status)
+	require.Nil(t, cancelled.NextRunAt)
+
+	_, err = testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:        scheduled.ID,
+		Amount:    20,
+		NextRunAt: scheduled.NextRunAt,
+	})
+	require.Error(t, err)
+}
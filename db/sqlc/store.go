@@ -3,10 +3,34 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
 )
 
-type Store struct {
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// Store provides all functions to execute db queries and transactions
+type Store interface {
+	Querier
+	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	DepositTx(ctx context.Context, arg EntryTxParams) (EntryTxResult, error)
+	WithdrawTx(ctx context.Context, arg EntryTxParams) (EntryTxResult, error)
+	CorrectBalanceTx(ctx context.Context, arg EntryTxParams) (EntryTxResult, error)
+	CrossCurrencyTransferTx(ctx context.Context, arg CrossCurrencyTransferTxParams) (TransferTxResult, error)
+	IdempotentTransferTx(ctx context.Context, arg IdempotentTransferTxParams) (TransferTxResult, error)
+	StatementTx(ctx context.Context, arg StatementTxParams) (StatementTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (TransferTxResult, error)
+	PlaceHoldTx(ctx context.Context, arg PlaceHoldTxParams) (Hold, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error)
+	ClaimScheduledTransferTx(ctx context.Context, arg ClaimScheduledTransferTxParams) (ScheduledTransferRun, error)
+	CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error)
+	RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams) (int, error)
+}
+
+// SQLStore provides all functions to execute SQL queries and transactions
+type SQLStore struct {
 	*Queries
 	db *sql.DB
 }
@@ -23,17 +47,23 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// Conversion is only set for cross-currency transfers
+	Conversion *TransferConversion `json:"conversion,omitempty"`
 }
 
-func NewStore(db *sql.DB) *Store {
-	return &Store{
+func NewStore(db *sql.DB) Store {
+	return &SQLStore{
 		db:      db,
 		Queries: New(db),
 	}
 }
 
-func (store *Store) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
+	return store.execTxWithOptions(ctx, nil, fn)
+}
+
+func (store *SQLStore) execTxWithOptions(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -50,49 +80,160 @@ func (store *Store) execTx(ctx context.Context, fn func(*Queries) error) error {
 	return tx.Commit()
 }
 
-func (store *Store) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
+		result, err = transferTx(ctx, q, arg, nil, nil)
+		return err
+	})
+
+	return result, err
+}
+
+// transferTx books the transfer within the given transaction. Reversals (reversalOf set) are booked by staff and
+// skip the overdraft and velocity checks.
+func transferTx(ctx context.Context, q *Queries, arg TransferTxParams, exchange *TransferExchange, reversalOf *int64) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
+
+	toAmount := arg.Amount
+	if exchange != nil {
+		toAmount = exchange.ToAmount
+	}
+
+	// lock both accounts in a consistent order so concurrent transfers in opposite directions cannot deadlock
+	var fromAccount Account
+	if arg.FromAccountID < arg.ToAccountID {
+		fromAccount, _, err = lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+	} else {
+		_, fromAccount, err = lockAccounts(ctx, q, arg.ToAccountID, arg.FromAccountID)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if reversalOf == nil {
+		err = checkFunds(ctx, q, fromAccount, arg.Amount)
 		if err != nil {
-			return err
+			return result, err
 		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
+		err = checkTransferLimits(ctx, q, fromAccount, arg.Amount)
 		if err != nil {
-			return err
+			return result, err
 		}
+	}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		ReversalOf:    reversalOf,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if exchange != nil {
+		if exchange.QuoteID != nil {
+			_, err = q.UseFxQuote(ctx, *exchange.QuoteID)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return result, ErrFxQuoteUnavailable
+				}
+				return result, err
+			}
+		}
+
+		conversion, err := q.CreateTransferConversion(ctx, CreateTransferConversionParams{
+			TransferID:   result.Transfer.ID,
+			FromCurrency: exchange.FromCurrency,
+			ToCurrency:   exchange.ToCurrency,
+			Rate:         exchange.Rate,
+			FromAmount:   arg.Amount,
+			ToAmount:     toAmount,
+			QuoteID:      exchange.QuoteID,
 		})
 		if err != nil {
-			return err
+			return result, err
 		}
+		result.Conversion = &conversion
+	}
 
-		// TODO: update accounts balance
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
-		}
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.FromAccountID,
+		Amount:     -arg.Amount,
+		TransferID: &result.Transfer.ID,
+	})
+	if err != nil {
+		return result, err
+	}
 
-		return err
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.ToAccountID,
+		Amount:     toAmount,
+		TransferID: &result.Transfer.ID,
 	})
+	if err != nil {
+		return result, err
+	}
+
+	// TODO: update accounts balance
+	if arg.FromAccountID < arg.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, toAmount)
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, toAmount, arg.FromAccountID, -arg.Amount)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	err = addOutboxEvent(ctx, q, AggregateTransfer, strconv.FormatInt(result.Transfer.ID, 10), EventTransferCreated, transferCreatedEvent{
+		Transfer:   result.Transfer,
+		Currency:   fromAccount.Currency,
+		Conversion: result.Conversion,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	err = addBalanceUpdatedEvent(ctx, q, result.FromAccount, result.FromEntry)
+	if err != nil {
+		return result, err
+	}
 
+	err = addBalanceUpdatedEvent(ctx, q, result.ToAccount, result.ToEntry)
 	return result, err
 }
 
+func lockAccounts(ctx context.Context, q *Queries, accountID1 int64, accountID2 int64) (account1 Account, account2 Account, err error) {
+	account1, err = q.GetAccountForUpdate(ctx, accountID1)
+	if err != nil {
+		return
+	}
+
+	account2, err = q.GetAccountForUpdate(ctx, accountID2)
+	return
+}
+
+// checkFunds returns ErrInsufficientFunds if debiting amount would take the available balance, which excludes
+// active holds, below the overdraft limit. The account must already be locked by the transaction.
+func checkFunds(ctx context.Context, q *Queries, account Account, amount int64) error {
+	held, err := q.GetHeldAmount(ctx, account.ID)
+	if err != nil {
+		return err
+	}
+
+	available := account.Balance - held
+	if available-amount < -account.OverdraftLimit {
+		return fmt.Errorf("%w: account [%d] available balance %d with overdraft limit %d cannot cover %d",
+			ErrInsufficientFunds, account.ID, available, account.OverdraftLimit, amount)
+	}
+	return nil
+}
+
 func addMoney(
 	ctx context.Context,
 	q *Queries,
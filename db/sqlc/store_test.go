@@ -3,7 +3,9 @@ package db
 import (
 	"context"
 	"fmt"
+	"simple_bank/util"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -159,3 +161,414 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 }
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	account1, err := store.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 100,
+	})
+	require.NoError(t, err)
+
+	// the overdraft limit can be used up exactly
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 100,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(-100), updatedAccount1.Balance)
+
+	updatedAccount2, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance+account1.Balance+100, updatedAccount2.Balance)
+}
+
+func TestTransferTxLimitExceeded(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	maxCount := int64(1)
+	maxAmount := int64(15)
+
+	_, err := store.UpsertAccountTransferLimit(context.Background(), UpsertAccountTransferLimitParams{
+		AccountID: &account1.ID,
+		Currency:  account1.Currency,
+		Period:    LimitPeriodDay,
+		MaxCount:  &maxCount,
+	})
+	require.NoError(t, err)
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	}
+
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	// user limits add up the transfers of all the owner's accounts in the currency
+	_, err = store.UpsertUserTransferLimit(context.Background(), UpsertUserTransferLimitParams{
+		Username:  &account2.Owner,
+		Currency:  account2.Currency,
+		Period:    LimitPeriodMonth,
+		MaxAmount: &maxAmount,
+	})
+	require.NoError(t, err)
+
+	arg = TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        10,
+	}
+
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+}
+
+func TestDepositTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	amount := util.RandomInt(1, 1000)
+
+	result, err := store.DepositTx(context.Background(), EntryTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+	})
+	require.NoError(t, err)
+
+	entry := result.Entry
+	require.NotZero(t, entry.ID)
+	require.Equal(t, account.ID, entry.AccountID)
+	require.Equal(t, amount, entry.Amount)
+
+	_, err = store.GetEntry(context.Background(), entry.ID)
+	require.NoError(t, err)
+
+	require.Equal(t, account.ID, result.Account.ID)
+	require.Equal(t, account.Balance+amount, result.Account.Balance)
+}
+
+func TestWithdrawTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	amount := util.RandomInt(1, 1000)
+
+	result, err := store.WithdrawTx(context.Background(), EntryTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+	})
+	require.NoError(t, err)
+
+	entry := result.Entry
+	require.NotZero(t, entry.ID)
+	require.Equal(t, account.ID, entry.AccountID)
+	require.Equal(t, -amount, entry.Amount)
+
+	_, err = store.GetEntry(context.Background(), entry.ID)
+	require.NoError(t, err)
+
+	require.Equal(t, account.ID, result.Account.ID)
+	require.Equal(t, account.Balance-amount, result.Account.Balance)
+}
+
+func TestWithdrawTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+
+	_, err := store.WithdrawTx(context.Background(), EntryTxParams{
+		AccountID: account.ID,
+		Amount:    account.Balance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updatedAccount, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, updatedAccount.Balance)
+}
+
+func TestCorrectBalanceTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	amount := -util.RandomInt(1, 1000)
+
+	result, err := store.CorrectBalanceTx(context.Background(), EntryTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, amount, result.Entry.Amount)
+	require.Equal(t, account.Balance+amount, result.Account.Balance)
+}
+
+func TestCrossCurrencyTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	arg := CrossCurrencyTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		},
+		Exchange: TransferExchange{
+			FromCurrency: account1.Currency,
+			ToCurrency:   account2.Currency,
+			Rate:         "1.5",
+			ToAmount:     15,
+		},
+	}
+
+	result, err := store.CrossCurrencyTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.Amount, result.Transfer.Amount)
+	require.Equal(t, -arg.Amount, result.FromEntry.Amount)
+	require.Equal(t, arg.Exchange.ToAmount, result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-arg.Amount, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+arg.Exchange.ToAmount, result.ToAccount.Balance)
+
+	require.NotNil(t, result.Conversion)
+	require.Equal(t, result.Transfer.ID, result.Conversion.TransferID)
+	require.Equal(t, arg.Exchange.ToAmount, result.Conversion.ToAmount)
+
+	conversion, err := store.GetTransferConversion(context.Background(), result.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, "1.5000000000", conversion.Rate)
+	require.Equal(t, arg.Exchange.FromCurrency, conversion.FromCurrency)
+	require.Equal(t, arg.Exchange.ToCurrency, conversion.ToCurrency)
+}
+
+func TestReverseTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	partial, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     4,
+	})
+	require.NoError(t, err)
+	require.Equal(t, &original.Transfer.ID, partial.Transfer.ReversalOf)
+	require.Equal(t, account2.ID, partial.Transfer.FromAccountID)
+	require.Equal(t, account1.ID, partial.Transfer.ToAccountID)
+	require.Equal(t, int64(4), partial.Transfer.Amount)
+	require.Nil(t, partial.Conversion)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     7,
+	})
+	require.ErrorIs(t, err, ErrInvalidReversalAmount)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: partial.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferNotReversible)
+
+	// the rest of the transfer is reversed by default
+	rest, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: original.Transfer.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(6), rest.Transfer.Amount)
+	require.Equal(t, account1.Balance, rest.ToAccount.Balance)
+	require.Equal(t, account2.Balance, rest.FromAccount.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: original.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferAlreadyReversed)
+}
+
+func TestReverseCrossCurrencyTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	original, err := store.CrossCurrencyTransferTx(context.Background(), CrossCurrencyTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		},
+		Exchange: TransferExchange{
+			FromCurrency: account1.Currency,
+			ToCurrency:   account2.Currency,
+			Rate:         "1.5",
+			ToAmount:     15,
+		},
+	})
+	require.NoError(t, err)
+
+	partial, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     3,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(5), partial.Transfer.Amount)
+	require.NotNil(t, partial.Conversion)
+	require.Equal(t, account2.Currency, partial.Conversion.FromCurrency)
+	require.Equal(t, int64(3), partial.Conversion.ToAmount)
+
+	// the last reversal takes back exactly what is left of the converted amount
+	rest, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: original.Transfer.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(10), rest.Transfer.Amount)
+	require.Equal(t, int64(7), rest.Conversion.ToAmount)
+	require.Equal(t, account1.Balance, rest.ToAccount.Balance)
+	require.Equal(t, account2.Balance, rest.FromAccount.Balance)
+}
+
+func TestReverseTransferTxRateMinorUnits(t *testing.T) {
+	store := NewStore(testDB)
+
+	usdAccount, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    createRandomUser(t).Username,
+		Balance:  100000,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+	krwAccount, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    createRandomUser(t).Username,
+		Balance:  100000,
+		Currency: "KRW",
+	})
+	require.NoError(t, err)
+
+	// $10.00 buy 13,000 won
+	original, err := store.CrossCurrencyTransferTx(context.Background(), CrossCurrencyTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: usdAccount.ID,
+			ToAccountID:   krwAccount.ID,
+			Amount:        1000,
+		},
+		Exchange: TransferExchange{
+			FromCurrency: "USD",
+			ToCurrency:   "KRW",
+			Rate:         "1300",
+			ToAmount:     13000,
+		},
+	})
+	require.NoError(t, err)
+
+	reversal, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: original.Transfer.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(13000), reversal.Transfer.Amount)
+	require.Equal(t, "KRW", reversal.Conversion.FromCurrency)
+	require.Equal(t, "USD", reversal.Conversion.ToCurrency)
+	require.Equal(t, int64(1000), reversal.Conversion.ToAmount)
+	// one won is worth 1/1300 of a dollar, not of a cent
+	require.Equal(t, "0.0007692308", reversal.Conversion.Rate)
+}
+
+func TestStatementTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	from := time.Now().Add(-time.Minute)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, &transfer.Transfer.ID, transfer.FromEntry.TransferID)
+	require.Equal(t, &transfer.Transfer.ID, transfer.ToEntry.TransferID)
+
+	_, err = store.DepositTx(context.Background(), EntryTxParams{AccountID: account1.ID, Amount: 5})
+	require.NoError(t, err)
+
+	result, err := store.StatementTx(context.Background(), StatementTxParams{
+		AccountID: account1.ID,
+		From:      from,
+		To:        time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account1.ID, result.Account.ID)
+	require.Zero(t, result.OpeningBalance)
+	require.Equal(t, int64(-5), result.LedgerBalance)
+	require.Len(t, result.Entries, 2)
+
+	require.Equal(t, int64(-10), result.Entries[0].Amount)
+	require.Equal(t, account1.ID, result.Entries[0].FromAccountID.Int64)
+	require.Equal(t, account2.ID, result.Entries[0].ToAccountID.Int64)
+
+	require.Equal(t, int64(5), result.Entries[1].Amount)
+	require.Nil(t, result.Entries[1].TransferID)
+	require.False(t, result.Entries[1].FromAccountID.Valid)
+}
+
+func TestIdempotentTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	arg := IdempotentTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		},
+		Key:         util.RandomString(16),
+		Username:    account1.Owner,
+		RequestHash: util.RandomString(64),
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+
+	result1, err := store.IdempotentTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, result1.Transfer.ID)
+
+	// replaying the same request must not move money again
+	result2, err := store.IdempotentTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, result1.Transfer.ID, result2.Transfer.ID)
+	require.Equal(t, result1.FromAccount.Balance, result2.FromAccount.Balance)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-arg.Amount, updatedAccount1.Balance)
+
+	// reusing the key for a different request is rejected
+	arg.RequestHash = util.RandomString(64)
+	_, err = store.IdempotentTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyMismatch)
+}
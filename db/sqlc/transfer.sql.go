@@ -7,26 +7,34 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
     from_account_id,
     to_account_id,
-    amount
+    amount,
+    reversal_of
 ) VALUES (
-    $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+    $1, $2, $3, $4
+) RETURNING id, from_account_id, to_account_id, amount, created_at, reversal_of
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	ReversalOf    *int64 `json:"reversal_of"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ReversalOf,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +42,34 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ReversalOf,
 	)
 	return i, err
 }
 
+const getReversalTotals = `-- name: GetReversalTotals :one
+SELECT
+    COALESCE(SUM(t.amount), 0)::bigint AS debited,
+    COALESCE(SUM(COALESCE(c.to_amount, t.amount)), 0)::bigint AS refunded
+FROM transfers t
+LEFT JOIN transfer_conversions c ON c.transfer_id = t.id
+WHERE t.reversal_of = $1::bigint
+`
+
+type GetReversalTotalsRow struct {
+	Debited  int64 `json:"debited"`
+	Refunded int64 `json:"refunded"`
+}
+
+func (q *Queries) GetReversalTotals(ctx context.Context, transferID int64) (GetReversalTotalsRow, error) {
+	row := q.db.QueryRowContext(ctx, getReversalTotals, transferID)
+	var i GetReversalTotalsRow
+	err := row.Scan(&i.Debited, &i.Refunded)
+	return i, err
+}
+
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -52,12 +82,107 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ReversalOf,
 	)
 	return i, err
 }
 
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ReversalOf,
+	)
+	return i, err
+}
+
+const listAccountTransfers = `-- name: ListAccountTransfers :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.reversal_of FROM transfers t
+LEFT JOIN transfer_conversions c ON c.transfer_id = t.id
+WHERE (
+    t.from_account_id = $1
+    OR t.to_account_id = $1
+  )
+  AND t.id < $2
+  AND t.created_at >= $3
+  AND t.created_at < $4
+  AND (
+    CASE WHEN t.to_account_id = $1 THEN COALESCE(c.to_amount, t.amount) ELSE t.amount END
+  ) BETWEEN $5::bigint AND $6::bigint
+  AND (
+    $7::text = ''
+    OR ($7::text = 'in' AND t.to_account_id = $1)
+    OR ($7::text = 'out' AND t.from_account_id = $1)
+  )
+ORDER BY t.id DESC
+LIMIT $8
+`
+
+type ListAccountTransfersParams struct {
+	AccountID int64     `json:"account_id"`
+	BeforeID  int64     `json:"before_id"`
+	FromTime  time.Time `json:"from_time"`
+	ToTime    time.Time `json:"to_time"`
+	MinAmount int64     `json:"min_amount"`
+	MaxAmount int64     `json:"max_amount"`
+	Direction string    `json:"direction"`
+	PageSize  int32     `json:"page_size"`
+}
+
+// The amount bounds are in the account's own currency: incoming cross-currency transfers
+// are filtered on the amount they credited.
+func (q *Queries) ListAccountTransfers(ctx context.Context, arg ListAccountTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountTransfers,
+		arg.AccountID,
+		arg.BeforeID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Direction,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ReversalOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of FROM transfers
 WHERE
     from_account_id = $1 OR
     to_account_id = $2
@@ -93,6 +218,7 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ReversalOf,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,81 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: transfer_conversion.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const createTransferConversion = `-- name: CreateTransferConversion :one
+INSERT INTO transfer_conversions (
+    transfer_id,
+    from_currency,
+    to_currency,
+    rate,
+    from_amount,
+    to_amount,
+    quote_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING transfer_id, from_currency, to_currency, rate, from_amount, to_amount, created_at, quote_id
+`
+
+type CreateTransferConversionParams struct {
+	TransferID   int64      `json:"transfer_id"`
+	FromCurrency string     `json:"from_currency"`
+	ToCurrency   string     `json:"to_currency"`
+	Rate         string     `json:"rate"`
+	FromAmount   int64      `json:"from_amount"`
+	ToAmount     int64      `json:"to_amount"`
+	QuoteID      *uuid.UUID `json:"quote_id"`
+}
+
+func (q *Queries) CreateTransferConversion(ctx context.Context, arg CreateTransferConversionParams) (TransferConversion, error) {
+	row := q.db.QueryRowContext(ctx, createTransferConversion,
+		arg.TransferID,
+		arg.FromCurrency,
+		arg.ToCurrency,
+		arg.Rate,
+		arg.FromAmount,
+		arg.ToAmount,
+		arg.QuoteID,
+	)
+	var i TransferConversion
+	err := row.Scan(
+		&i.TransferID,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.Rate,
+		&i.FromAmount,
+		&i.ToAmount,
+		&i.CreatedAt,
+		&i.QuoteID,
+	)
+	return i, err
+}
+
+const getTransferConversion = `-- name: GetTransferConversion :one
+SELECT transfer_id, from_currency, to_currency, rate, from_amount, to_amount, created_at, quote_id FROM transfer_conversions
+WHERE transfer_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetTransferConversion(ctx context.Context, transferID int64) (TransferConversion, error) {
+	row := q.db.QueryRowContext(ctx, getTransferConversion, transferID)
+	var i TransferConversion
+	err := row.Scan(
+		&i.TransferID,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.Rate,
+		&i.FromAmount,
+		&i.ToAmount,
+		&i.CreatedAt,
+		&i.QuoteID,
+	)
+	return i, err
+}
@@ -0,0 +1,298 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: transfer_limit.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const deleteTransferLimit = `-- name: DeleteTransferLimit :exec
+DELETE FROM transfer_limits
+WHERE id = $1
+`
+
+func (q *Queries) DeleteTransferLimit(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteTransferLimit, id)
+	return err
+}
+
+const getAccountOutgoingTotals = `-- name: GetAccountOutgoingTotals :one
+SELECT
+    COUNT(*)::bigint AS count,
+    COALESCE(SUM(amount), 0)::bigint AS amount
+FROM transfers
+WHERE from_account_id = $1
+  AND reversal_of IS NULL
+  AND created_at >= $2
+`
+
+type GetAccountOutgoingTotalsParams struct {
+	AccountID int64     `json:"account_id"`
+	Since     time.Time `json:"since"`
+}
+
+type GetAccountOutgoingTotalsRow struct {
+	Count  int64 `json:"count"`
+	Amount int64 `json:"amount"`
+}
+
+func (q *Queries) GetAccountOutgoingTotals(ctx context.Context, arg GetAccountOutgoingTotalsParams) (GetAccountOutgoingTotalsRow, error) {
+	row := q.db.QueryRowContext(ctx, getAccountOutgoingTotals, arg.AccountID, arg.Since)
+	var i GetAccountOutgoingTotalsRow
+	err := row.Scan(&i.Count, &i.Amount)
+	return i, err
+}
+
+const getUserOutgoingTotals = `-- name: GetUserOutgoingTotals :one
+SELECT
+    COUNT(*)::bigint AS count,
+    COALESCE(SUM(t.amount), 0)::bigint AS amount
+FROM transfers t
+JOIN accounts a ON a.id = t.from_account_id
+WHERE a.owner = $1
+  AND a.currency = $2
+  AND t.reversal_of IS NULL
+  AND t.created_at >= $3
+`
+
+type GetUserOutgoingTotalsParams struct {
+	Owner    string    `json:"owner"`
+	Currency string    `json:"currency"`
+	Since    time.Time `json:"since"`
+}
+
+type GetUserOutgoingTotalsRow struct {
+	Count  int64 `json:"count"`
+	Amount int64 `json:"amount"`
+}
+
+func (q *Queries) GetUserOutgoingTotals(ctx context.Context, arg GetUserOutgoingTotalsParams) (GetUserOutgoingTotalsRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserOutgoingTotals, arg.Owner, arg.Currency, arg.Since)
+	var i GetUserOutgoingTotalsRow
+	err := row.Scan(&i.Count, &i.Amount)
+	return i, err
+}
+
+const listAccountTransferLimits = `-- name: ListAccountTransferLimits :many
+SELECT id, username, account_id, currency, period, max_count, max_amount, created_at, updated_at FROM transfer_limits
+WHERE account_id = $1::bigint
+ORDER BY id
+`
+
+func (q *Queries) ListAccountTransferLimits(ctx context.Context, accountID int64) ([]TransferLimit, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountTransferLimits, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TransferLimit{}
+	for rows.Next() {
+		var i TransferLimit
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.AccountID,
+			&i.Currency,
+			&i.Period,
+			&i.MaxCount,
+			&i.MaxAmount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listApplicableTransferLimits = `-- name: ListApplicableTransferLimits :many
+SELECT id, username, account_id, currency, period, max_count, max_amount, created_at, updated_at FROM transfer_limits
+WHERE currency = $1
+  AND (username = $2::varchar OR account_id = $3::bigint)
+ORDER BY id
+`
+
+type ListApplicableTransferLimitsParams struct {
+	Currency  string `json:"currency"`
+	Username  string `json:"username"`
+	AccountID int64  `json:"account_id"`
+}
+
+func (q *Queries) ListApplicableTransferLimits(ctx context.Context, arg ListApplicableTransferLimitsParams) ([]TransferLimit, error) {
+	rows, err := q.db.QueryContext(ctx, listApplicableTransferLimits, arg.Currency, arg.Username, arg.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TransferLimit{}
+	for rows.Next() {
+		var i TransferLimit
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.AccountID,
+			&i.Currency,
+			&i.Period,
+			&i.MaxCount,
+			&i.MaxAmount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserTransferLimits = `-- name: ListUserTransferLimits :many
+SELECT id, username, account_id, currency, period, max_count, max_amount, created_at, updated_at FROM transfer_limits
+WHERE username = $1::varchar
+ORDER BY id
+`
+
+func (q *Queries) ListUserTransferLimits(ctx context.Context, username string) ([]TransferLimit, error) {
+	rows, err := q.db.QueryContext(ctx, listUserTransferLimits, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TransferLimit{}
+	for rows.Next() {
+		var i TransferLimit
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.AccountID,
+			&i.Currency,
+			&i.Period,
+			&i.MaxCount,
+			&i.MaxAmount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertAccountTransferLimit = `-- name: UpsertAccountTransferLimit :one
+INSERT INTO transfer_limits (
+    account_id,
+    currency,
+    period,
+    max_count,
+    max_amount
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (account_id, currency, period) DO UPDATE
+SET max_count = EXCLUDED.max_count,
+    max_amount = EXCLUDED.max_amount,
+    updated_at = now()
+RETURNING id, username, account_id, currency, period, max_count, max_amount, created_at, updated_at
+`
+
+type UpsertAccountTransferLimitParams struct {
+	AccountID *int64 `json:"account_id"`
+	Currency  string `json:"currency"`
+	Period    string `json:"period"`
+	MaxCount  *int64 `json:"max_count"`
+	MaxAmount *int64 `json:"max_amount"`
+}
+
+func (q *Queries) UpsertAccountTransferLimit(ctx context.Context, arg UpsertAccountTransferLimitParams) (TransferLimit, error) {
+	row := q.db.QueryRowContext(ctx, upsertAccountTransferLimit,
+		arg.AccountID,
+		arg.Currency,
+		arg.Period,
+		arg.MaxCount,
+		arg.MaxAmount,
+	)
+	var i TransferLimit
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.AccountID,
+		&i.Currency,
+		&i.Period,
+		&i.MaxCount,
+		&i.MaxAmount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertUserTransferLimit = `-- name: UpsertUserTransferLimit :one
+INSERT INTO transfer_limits (
+    username,
+    currency,
+    period,
+    max_count,
+    max_amount
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (username, currency, period) DO UPDATE
+SET max_count = EXCLUDED.max_count,
+    max_amount = EXCLUDED.max_amount,
+    updated_at = now()
+RETURNING id, username, account_id, currency, period, max_count, max_amount, created_at, updated_at
+`
+
+type UpsertUserTransferLimitParams struct {
+	Username  *string `json:"username"`
+	Currency  string  `json:"currency"`
+	Period    string  `json:"period"`
+	MaxCount  *int64  `json:"max_count"`
+	MaxAmount *int64  `json:"max_amount"`
+}
+
+func (q *Queries) UpsertUserTransferLimit(ctx context.Context, arg UpsertUserTransferLimitParams) (TransferLimit, error) {
+	row := q.db.QueryRowContext(ctx, upsertUserTransferLimit,
+		arg.Username,
+		arg.Currency,
+		arg.Period,
+		arg.MaxCount,
+		arg.MaxAmount,
+	)
+	var i TransferLimit
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.AccountID,
+		&i.Currency,
+		&i.Period,
+		&i.MaxCount,
+		&i.MaxAmount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
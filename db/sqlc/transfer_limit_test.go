@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertTransferLimits(t *testing.T) {
+	account := createRandomAccount(t)
+	maxCount := int64(3)
+	maxAmount := int64(100)
+
+	userLimit, err := testQueries.UpsertUserTransferLimit(context.Background(), UpsertUserTransferLimitParams{
+		Username: &account.Owner,
+		Currency: account.Currency,
+		Period:   LimitPeriodDay,
+		MaxCount: &maxCount,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Owner, *userLimit.Username)
+	require.Nil(t, userLimit.AccountID)
+	require.Nil(t, userLimit.MaxAmount)
+
+	// setting the same period again replaces the limit
+	updatedLimit, err := testQueries.UpsertUserTransferLimit(context.Background(), UpsertUserTransferLimitParams{
+		Username:  &account.Owner,
+		Currency:  account.Currency,
+		Period:    LimitPeriodDay,
+		MaxAmount: &maxAmount,
+	})
+	require.NoError(t, err)
+	require.Equal(t, userLimit.ID, updatedLimit.ID)
+	require.Nil(t, updatedLimit.MaxCount)
+	require.Equal(t, maxAmount, *updatedLimit.MaxAmount)
+
+	accountLimit, err := testQueries.UpsertAccountTransferLimit(context.Background(), UpsertAccountTransferLimitParams{
+		AccountID: &account.ID,
+		Currency:  account.Currency,
+		Period:    LimitPeriodMonth,
+		MaxCount:  &maxCount,
+	})
+	require.NoError(t, err)
+
+	limits, err := testQueries.ListApplicableTransferLimits(context.Background(), ListApplicableTransferLimitsParams{
+		Currency:  account.Currency,
+		Username:  account.Owner,
+		AccountID: account.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, limits, 2)
+
+	err = testQueries.DeleteTransferLimit(context.Background(), accountLimit.ID)
+	require.NoError(t, err)
+
+	limits, err = testQueries.ListAccountTransferLimits(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Empty(t, limits)
+
+	limits, err = testQueries.ListUserTransferLimits(context.Background(), account.Owner)
+	require.NoError(t, err)
+	require.Len(t, limits, 1)
+}
+
+func TestOutgoingTotalsExcludeReversals(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	since := time.Now().Add(-time.Minute)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// the refund is sent from account2, but it wasn't its owner's doing
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: original.Transfer.ID})
+	require.NoError(t, err)
+
+	accountTotals, err := testQueries.GetAccountOutgoingTotals(context.Background(), GetAccountOutgoingTotalsParams{
+		AccountID: account2.ID,
+		Since:     since,
+	})
+	require.NoError(t, err)
+	require.Zero(t, accountTotals.Count)
+	require.Zero(t, accountTotals.Amount)
+
+	userTotals, err := testQueries.GetUserOutgoingTotals(context.Background(), GetUserOutgoingTotalsParams{
+		Owner:    account2.Owner,
+		Currency: account2.Currency,
+		Since:    since,
+	})
+	require.NoError(t, err)
+	require.Zero(t, userTotals.Count)
+	require.Zero(t, userTotals.Amount)
+
+	accountTotals, err = testQueries.GetAccountOutgoingTotals(context.Background(), GetAccountOutgoingTotalsParams{
+		AccountID: account1.ID,
+		Since:     since,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), accountTotals.Count)
+	require.Equal(t, int64(10), accountTotals.Amount)
+}
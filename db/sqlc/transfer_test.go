@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAccountTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	for i := int64(1); i <= 4; i++ {
+		from, to := account1, account2
+		if i%2 == 0 {
+			from, to = account2, account1
+		}
+
+		_, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        i * 10,
+		})
+		require.NoError(t, err)
+	}
+
+	arg := ListAccountTransfersParams{
+		AccountID: account1.ID,
+		BeforeID:  math.MaxInt64,
+		ToTime:    time.Now().Add(time.Minute),
+		MaxAmount: math.MaxInt64,
+		PageSize:  10,
+	}
+
+	transfers, err := testQueries.ListAccountTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 4)
+	for i := 1; i < len(transfers); i++ {
+		require.Less(t, transfers[i].ID, transfers[i-1].ID)
+	}
+
+	arg.Direction = "in"
+	transfers, err = testQueries.ListAccountTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+	for _, transfer := range transfers {
+		require.Equal(t, account1.ID, transfer.ToAccountID)
+	}
+
+	arg.Direction = ""
+	arg.MinAmount = 20
+	arg.MaxAmount = 30
+	transfers, err = testQueries.ListAccountTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+}
+
+func TestListAccountTransfersCrossCurrencyAmount(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// account2 is credited 1500 of its own currency for 10 of account1's
+	result, err := store.CrossCurrencyTransferTx(context.Background(), CrossCurrencyTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		},
+		Exchange: TransferExchange{
+			FromCurrency: account1.Currency,
+			ToCurrency:   account2.Currency,
+			Rate:         "150",
+			ToAmount:     1500,
+		},
+	})
+	require.NoError(t, err)
+
+	arg := ListAccountTransfersParams{
+		AccountID: account2.ID,
+		BeforeID:  math.MaxInt64,
+		ToTime:    time.Now().Add(time.Minute),
+		MinAmount: 1000,
+		MaxAmount: 2000,
+		Direction: "in",
+		PageSize:  10,
+	}
+
+	transfers, err := testQueries.ListAccountTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, result.Transfer.ID, transfers[0].ID)
+
+	// the sender still filters on the amount it was debited
+	arg.AccountID = account1.ID
+	arg.Direction = "out"
+	transfers, err = testQueries.ListAccountTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+
+	arg.MinAmount = 10
+	arg.MaxAmount = 10
+	transfers, err = testQueries.ListAccountTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+}
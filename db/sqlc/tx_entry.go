@@ -0,0 +1,70 @@
+package db
+
+import "context"
+
+type EntryTxParams struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+}
+
+type EntryTxResult struct {
+	Account Account `json:"account"`
+	Entry   Entry   `json:"entry"`
+}
+
+// DepositTx credits the account and records the matching ledger entry in a single transaction
+func (store *SQLStore) DepositTx(ctx context.Context, arg EntryTxParams) (EntryTxResult, error) {
+	return store.entryTx(ctx, arg.AccountID, arg.Amount, false)
+}
+
+// WithdrawTx debits the account and records the matching ledger entry in a single transaction
+func (store *SQLStore) WithdrawTx(ctx context.Context, arg EntryTxParams) (EntryTxResult, error) {
+	return store.entryTx(ctx, arg.AccountID, -arg.Amount, true)
+}
+
+// CorrectBalanceTx books a signed correction against the account and records the matching ledger entry.
+// Corrections are not bound by the overdraft limit.
+func (store *SQLStore) CorrectBalanceTx(ctx context.Context, arg EntryTxParams) (EntryTxResult, error) {
+	return store.entryTx(ctx, arg.AccountID, arg.Amount, false)
+}
+
+// entryTx books amount against the account. With enforceLimit set, a debit fails with ErrInsufficientFunds
+// when it would exceed the overdraft limit.
+func (store *SQLStore) entryTx(ctx context.Context, accountID int64, amount int64, enforceLimit bool) (EntryTxResult, error) {
+	var result EntryTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		if enforceLimit {
+			account, err := q.GetAccountForUpdate(ctx, accountID)
+			if err != nil {
+				return err
+			}
+
+			err = checkFunds(ctx, q, account, -amount)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: accountID,
+			Amount:    amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     accountID,
+			Amount: amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		return addBalanceUpdatedEvent(ctx, q, result.Account, result.Entry)
+	})
+
+	return result, err
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var ErrFxQuoteUnavailable = errors.New("fx quote has expired or has already been used")
+
+// TransferExchange describes how the amount debited from the source account converts
+// into the amount credited to the destination account
+type TransferExchange struct {
+	FromCurrency string `json:"from_currency"`
+	ToCurrency   string `json:"to_currency"`
+	Rate         string `json:"rate"`
+	ToAmount     int64  `json:"to_amount"`
+	// QuoteID is set when the rate comes from a quote, which the transfer consumes
+	QuoteID *uuid.UUID `json:"quote_id,omitempty"`
+}
+
+type CrossCurrencyTransferTxParams struct {
+	TransferTxParams
+	Exchange TransferExchange `json:"exchange"`
+}
+
+// CrossCurrencyTransferTx moves money between accounts of different currencies. Amount is debited
+// in the source currency, Exchange.ToAmount is credited in the destination currency and the rate
+// used is recorded alongside the transfer.
+func (store *SQLStore) CrossCurrencyTransferTx(ctx context.Context, arg CrossCurrencyTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = transferTx(ctx, q, arg.TransferTxParams, &arg.Exchange, nil)
+		return err
+	})
+
+	return result, err
+}
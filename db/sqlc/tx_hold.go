@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	HoldStatusActive   = "active"
+	HoldStatusCaptured = "captured"
+	HoldStatusReleased = "released"
+	HoldStatusExpired  = "expired"
+)
+
+var (
+	ErrHoldNotActive        = errors.New("hold has already been captured, released or has expired")
+	ErrInvalidCaptureAmount = errors.New("capture amount exceeds the hold")
+)
+
+type PlaceHoldTxParams struct {
+	AccountID int64     `json:"account_id"`
+	Amount    int64     `json:"amount"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PlaceHoldTx reserves part of the available balance until the hold is captured, released or expires
+func (store *SQLStore) PlaceHoldTx(ctx context.Context, arg PlaceHoldTxParams) (Hold, error) {
+	var hold Hold
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		err = checkFunds(ctx, q, account, arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		hold, err = q.CreateHold(ctx, CreateHoldParams(arg))
+		return err
+	})
+
+	return hold, err
+}
+
+type CaptureHoldTxParams struct {
+	HoldID      int64 `json:"hold_id"`
+	ToAccountID int64 `json:"to_account_id"`
+	// Amount defaults to the whole hold. Whatever is not captured is given back.
+	Amount int64 `json:"amount"`
+}
+
+type CaptureHoldTxResult struct {
+	Hold     Hold             `json:"hold"`
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// CaptureHoldTx turns an active hold into a transfer from the held account
+func (store *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error) {
+	var result CaptureHoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// the hold stops counting against the available balance before the transfer checks it
+		hold, err := q.CaptureHold(ctx, arg.HoldID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrHoldNotActive
+			}
+			return err
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount < 0 || amount > hold.Amount {
+			return fmt.Errorf("%w: hold [%d] is for %d", ErrInvalidCaptureAmount, hold.ID, hold.Amount)
+		}
+
+		result.Transfer, err = transferTx(ctx, q, TransferTxParams{
+			FromAccountID: hold.AccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        amount,
+		}, nil, nil)
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.SetHoldTransfer(ctx, SetHoldTransferParams{
+			ID:         hold.ID,
+			TransferID: &result.Transfer.Transfer.ID,
+		})
+		return err
+	})
+
+	return result, err
+}
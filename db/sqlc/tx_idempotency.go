@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key has already been used with a different request")
+	ErrIdempotencyKeyConflict = errors.New("idempotency key is being used by a concurrent request")
+)
+
+type IdempotentTransferTxParams struct {
+	TransferTxParams
+	// Exchange is set when the transfer converts between currencies
+	Exchange    *TransferExchange `json:"exchange"`
+	Key         string            `json:"key"`
+	Username    string            `json:"username"`
+	RequestHash string            `json:"request_hash"`
+	ExpiresAt   time.Time         `json:"expires_at"`
+}
+
+// IdempotentTransferTx performs TransferTx at most once per idempotency key.
+// A replay of the same request returns the stored result of the first call.
+func (store *SQLStore) IdempotentTransferTx(ctx context.Context, arg IdempotentTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		key, err := q.GetIdempotencyKeyForUpdate(ctx, GetIdempotencyKeyForUpdateParams{
+			Username: arg.Username,
+			Key:      arg.Key,
+		})
+		switch {
+		case err == nil && time.Now().After(key.ExpiresAt):
+			err = q.DeleteIdempotencyKey(ctx, DeleteIdempotencyKeyParams{
+				Username: arg.Username,
+				Key:      arg.Key,
+			})
+			if err != nil {
+				return err
+			}
+		case err == nil:
+			if key.RequestHash != arg.RequestHash {
+				return ErrIdempotencyKeyMismatch
+			}
+			return json.Unmarshal(key.Response, &result)
+		case err != sql.ErrNoRows:
+			return err
+		}
+
+		result, err = transferTx(ctx, q, arg.TransferTxParams, arg.Exchange, nil)
+		if err != nil {
+			return err
+		}
+
+		response, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+			Key:         arg.Key,
+			Username:    arg.Username,
+			RequestHash: arg.RequestHash,
+			Response:    response,
+			ExpiresAt:   arg.ExpiresAt,
+		})
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return ErrIdempotencyKeyConflict
+		}
+		return err
+	})
+
+	return result, err
+}
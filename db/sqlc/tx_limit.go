@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	LimitPeriodDay   = "day"
+	LimitPeriodMonth = "month"
+)
+
+var ErrTransferLimitExceeded = errors.New("transfer limit exceeded")
+
+// periodStart returns the start of the UTC day or month containing t
+func periodStart(period string, t time.Time) time.Time {
+	t = t.UTC()
+	if period == LimitPeriodMonth {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// checkTransferLimits returns ErrTransferLimitExceeded if sending amount from the account would exceed one of
+// the velocity limits set on the account or its owner. The account must already be locked by the transaction.
+func checkTransferLimits(ctx context.Context, q *Queries, account Account, amount int64) error {
+	limits, err := q.ListApplicableTransferLimits(ctx, ListApplicableTransferLimitsParams{
+		Currency:  account.Currency,
+		Username:  account.Owner,
+		AccountID: account.ID,
+	})
+	if err != nil || len(limits) == 0 {
+		return err
+	}
+
+	now := time.Now()
+	userLocked := false
+
+	for _, limit := range limits {
+		since := periodStart(limit.Period, now)
+
+		var count, total int64
+		if limit.AccountID != nil {
+			totals, err := q.GetAccountOutgoingTotals(ctx, GetAccountOutgoingTotalsParams{
+				AccountID: account.ID,
+				Since:     since,
+			})
+			if err != nil {
+				return err
+			}
+			count, total = totals.Count, totals.Amount
+		} else {
+			// the owner's other accounts are not locked, so serialize their transfers on the user row
+			if !userLocked {
+				if err := q.LockUser(ctx, account.Owner); err != nil {
+					return err
+				}
+				userLocked = true
+			}
+
+			totals, err := q.GetUserOutgoingTotals(ctx, GetUserOutgoingTotalsParams{
+				Owner:    account.Owner,
+				Currency: account.Currency,
+				Since:    since,
+			})
+			if err != nil {
+				return err
+			}
+			count, total = totals.Count, totals.Amount
+		}
+
+		if limit.MaxCount != nil && count+1 > *limit.MaxCount {
+			return fmt.Errorf("%w: at most %d %s transfers per %s", ErrTransferLimitExceeded, *limit.MaxCount, limit.Currency, limit.Period)
+		}
+		if limit.MaxAmount != nil && total+amount > *limit.MaxAmount {
+			return fmt.Errorf("%w: at most %d %s per %s", ErrTransferLimitExceeded, *limit.MaxAmount, limit.Currency, limit.Period)
+		}
+	}
+
+	return nil
+}
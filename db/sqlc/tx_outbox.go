@@ -0,0 +1,168 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	AggregateTransfer = "transfer"
+	AggregateUser     = "user"
+	AggregateAccount  = "account"
+)
+
+const (
+	EventTransferCreated = "transfer.created"
+	EventUserCreated     = "user.created"
+	EventAccountCreated  = "account.created"
+	// EventAccountBalanceUpdated is recorded for every entry booked against an account
+	EventAccountBalanceUpdated = "account.balance_updated"
+)
+
+// outboxRelayLockID is the advisory lock that lets only one relay publish at a time, which keeps events in order
+const outboxRelayLockID = 4_119_005_522
+
+type transferCreatedEvent struct {
+	Transfer
+	Currency string `json:"currency"`
+	// Conversion is only set for cross-currency transfers
+	Conversion *TransferConversion `json:"conversion,omitempty"`
+}
+
+type balanceUpdatedEvent struct {
+	AccountID int64  `json:"account_id"`
+	Owner     string `json:"owner"`
+	Currency  string `json:"currency"`
+	Balance   int64  `json:"balance"`
+	EntryID   int64  `json:"entry_id"`
+	Amount    int64  `json:"amount"`
+}
+
+// userCreatedEvent leaves out the hashed password
+type userCreatedEvent struct {
+	Username  string    `json:"username"`
+	FullName  string    `json:"full_name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// addOutboxEvent records an event in the same transaction as the change it describes,
+// so it is published if and only if the change commits
+func addOutboxEvent(ctx context.Context, q *Queries, aggregateType string, aggregateID string, eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("cannot encode %s event: %w", eventType, err)
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       data,
+	})
+	return err
+}
+
+// addBalanceUpdatedEvent records the balance of account after entry was booked against it
+func addBalanceUpdatedEvent(ctx context.Context, q *Queries, account Account, entry Entry) error {
+	return addOutboxEvent(ctx, q, AggregateAccount, strconv.FormatInt(account.ID, 10), EventAccountBalanceUpdated, balanceUpdatedEvent{
+		AccountID: account.ID,
+		Owner:     account.Owner,
+		Currency:  account.Currency,
+		Balance:   account.Balance,
+		EntryID:   entry.ID,
+		Amount:    entry.Amount,
+	})
+}
+
+// CreateUserTx creates a user and records a user.created event
+func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		user, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return addOutboxEvent(ctx, q, AggregateUser, user.Username, EventUserCreated, userCreatedEvent{
+			Username:  user.Username,
+			FullName:  user.FullName,
+			Email:     user.Email,
+			Role:      user.Role,
+			CreatedAt: user.CreateAt,
+		})
+	})
+
+	return user, err
+}
+
+// CreateAccountTx creates an account and records an account.created event
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		account, err = q.CreateAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return addOutboxEvent(ctx, q, AggregateAccount, strconv.FormatInt(account.ID, 10), EventAccountCreated, account)
+	})
+
+	return account, err
+}
+
+type RelayOutboxTxParams struct {
+	Limit int32
+	// Publish is called for each event, oldest first. The batch stops at the first error so
+	// later events are not published ahead of it.
+	Publish func(ctx context.Context, event OutboxEvent) error
+}
+
+// RelayOutboxTx publishes a batch of unpublished events in order and marks them published. It publishes
+// nothing while another relay holds the batch. An event may be published again if the transaction
+// fails after publishing it, so delivery is at least once.
+func (store *SQLStore) RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams) (int, error) {
+	published := 0
+	var publishErr error
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		locked, err := q.LockOutboxRelay(ctx, outboxRelayLockID)
+		if err != nil || !locked {
+			return err
+		}
+
+		events, err := q.ListUnpublishedOutboxEvents(ctx, arg.Limit)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if err := arg.Publish(ctx, event); err != nil {
+				// keep what was published so far and retry from this event next time
+				publishErr = fmt.Errorf("cannot publish event [%d]: %w", event.ID, err)
+				return nil
+			}
+
+			if err = q.MarkOutboxEventPublished(ctx, event.ID); err != nil {
+				return err
+			}
+			published++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return published, publishErr
+}
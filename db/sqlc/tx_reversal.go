@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"simple_bank/currency"
+)
+
+var (
+	ErrTransferNotReversible   = errors.New("reversals cannot be reversed")
+	ErrTransferAlreadyReversed = errors.New("transfer has already been fully reversed")
+	ErrInvalidReversalAmount   = errors.New("invalid reversal amount")
+)
+
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount is the part of the original amount to give back, in the currency it was sent in.
+	// Zero reverses whatever has not been reversed yet.
+	Amount int64 `json:"amount"`
+}
+
+// ReverseTransferTx gives back all or part of a transfer with a compensating transfer linked to it by reversal_of.
+// Cross-currency transfers are reversed at their original rate.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// locking the original transfer serializes concurrent reversals of it
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		if original.ReversalOf != nil {
+			return ErrTransferNotReversible
+		}
+
+		totals, err := q.GetReversalTotals(ctx, original.ID)
+		if err != nil {
+			return err
+		}
+
+		remaining := original.Amount - totals.Refunded
+		if remaining <= 0 {
+			return ErrTransferAlreadyReversed
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount < 0 || amount > remaining {
+			return fmt.Errorf("%w: %d of transfer [%d] is left to reverse", ErrInvalidReversalAmount, remaining, original.ID)
+		}
+
+		reversal := TransferTxParams{
+			FromAccountID: original.ToAccountID,
+			ToAccountID:   original.FromAccountID,
+			Amount:        amount,
+		}
+
+		var exchange *TransferExchange
+		conversion, err := q.GetTransferConversion(ctx, original.ID)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if err == nil {
+			// the last reversal takes back whatever is left so rounding never leaves a remainder
+			debit := conversion.ToAmount - totals.Debited
+			if amount < remaining {
+				debit = proportion(amount, conversion.ToAmount, conversion.FromAmount)
+			}
+			if debit <= 0 {
+				return fmt.Errorf("%w: %d %s is too small to convert back", ErrInvalidReversalAmount, amount, conversion.FromCurrency)
+			}
+
+			rate, err := reversalRate(conversion, amount, debit)
+			if err != nil {
+				return err
+			}
+
+			reversal.Amount = debit
+			exchange = &TransferExchange{
+				FromCurrency: conversion.ToCurrency,
+				ToCurrency:   conversion.FromCurrency,
+				Rate:         rate,
+				ToAmount:     amount,
+			}
+		}
+
+		result, err = transferTx(ctx, q, reversal, exchange, &original.ID)
+		return err
+	})
+
+	return result, err
+}
+
+// reversalRate returns the rate at which debiting debit of the original's target currency refunds amount
+// of its source currency. Like every rate it is quoted in major units, so it is scaled by the minor units
+// of both currencies.
+func reversalRate(conversion TransferConversion, amount int64, debit int64) (string, error) {
+	from, err := currency.Lookup(conversion.ToCurrency)
+	if err != nil {
+		return "", err
+	}
+	to, err := currency.Lookup(conversion.FromCurrency)
+	if err != nil {
+		return "", err
+	}
+
+	rate := new(big.Rat).SetFrac64(amount, debit)
+	rate.Quo(rate, currency.MinorUnitScale(from, to))
+	return rate.FloatString(10), nil
+}
+
+// proportion returns amount * numerator / denominator rounded half away from zero, for positive values
+func proportion(amount int64, numerator int64, denominator int64) int64 {
+	n := new(big.Int).Mul(big.NewInt(amount), big.NewInt(numerator))
+	n.Mul(n, big.NewInt(2))
+	n.Add(n, big.NewInt(denominator))
+
+	d := big.NewInt(2 * denominator)
+	return n.Quo(n, d).Int64()
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	ScheduledTransferStatusActive    = "active"
+	ScheduledTransferStatusCompleted = "completed"
+	ScheduledTransferStatusCancelled = "cancelled"
+)
+
+const (
+	ScheduledRunStatusPending   = "pending"
+	ScheduledRunStatusSucceeded = "succeeded"
+	ScheduledRunStatusFailed    = "failed"
+)
+
+var ErrScheduledRunClaimed = errors.New("scheduled transfer run has already been claimed")
+
+type ClaimScheduledTransferTxParams struct {
+	ID int64 `json:"id"`
+	// ScheduledFor is the next_run_at the caller saw; the claim fails if it has moved since
+	ScheduledFor time.Time `json:"scheduled_for"`
+	// NextRunAt is nil when this is the last run
+	NextRunAt *time.Time `json:"next_run_at"`
+}
+
+// ClaimScheduledTransferTx records a pending run for a due scheduled transfer and moves it on to its next run.
+// A claimed run is never claimed again, so the transfer behind it is attempted at most once.
+func (store *SQLStore) ClaimScheduledTransferTx(ctx context.Context, arg ClaimScheduledTransferTxParams) (ScheduledTransferRun, error) {
+	var run ScheduledTransferRun
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		scheduled, err := q.GetScheduledTransferForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if scheduled.Status != ScheduledTransferStatusActive || scheduled.NextRunAt == nil || !scheduled.NextRunAt.Equal(arg.ScheduledFor) {
+			return ErrScheduledRunClaimed
+		}
+
+		run, err = q.CreateScheduledTransferRun(ctx, CreateScheduledTransferRunParams{
+			ScheduledTransferID: scheduled.ID,
+			ScheduledFor:        arg.ScheduledFor,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.AdvanceScheduledTransfer(ctx, AdvanceScheduledTransferParams{
+			ID:        scheduled.ID,
+			NextRunAt: arg.NextRunAt,
+		})
+		return err
+	})
+
+	return run, err
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type StatementTxParams struct {
+	AccountID int64     `json:"account_id"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+}
+
+type StatementTxResult struct {
+	Account        Account                   `json:"account"`
+	OpeningBalance int64                     `json:"opening_balance"`
+	Entries        []ListStatementEntriesRow `json:"entries"`
+	LedgerBalance  int64                     `json:"ledger_balance"`
+}
+
+// StatementTx reads everything a statement needs from a single snapshot, so entries written
+// concurrently can't make the balances disagree with each other
+func (store *SQLStore) StatementTx(ctx context.Context, arg StatementTxParams) (StatementTxResult, error) {
+	var result StatementTxResult
+
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := store.execTxWithOptions(ctx, opts, func(q *Queries) error {
+		var err error
+
+		result.Account, err = q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		result.OpeningBalance, err = q.GetEntriesTotal(ctx, GetEntriesTotalParams{
+			AccountID: arg.AccountID,
+			Before:    arg.From,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Entries, err = q.ListStatementEntries(ctx, ListStatementEntriesParams{
+			AccountID: arg.AccountID,
+			FromTime:  arg.From,
+			ToTime:    arg.To,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.LedgerBalance, err = q.GetLedgerBalance(ctx, arg.AccountID)
+		return err
+	})
+
+	return result, err
+}
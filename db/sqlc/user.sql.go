@@ -17,7 +17,7 @@ INSERT INTO users (
     email
 ) VALUES (
     $1, $2, $3, $4
-) RETURNING username, hashed_password, full_name, email, password_changed_at, create_at
+) RETURNING username, hashed_password, full_name, email, password_changed_at, create_at, role
 `
 
 type CreateUserParams struct {
@@ -42,12 +42,13 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreateAt,
+		&i.Role,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, password_changed_at, create_at FROM users
+SELECT username, hashed_password, full_name, email, password_changed_at, create_at, role FROM users
 WHERE username = $1 LIMIT 1
 `
 
@@ -61,6 +62,45 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreateAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const lockUser = `-- name: LockUser :exec
+SELECT username FROM users
+WHERE username = $1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) LockUser(ctx context.Context, username string) error {
+	_, err := q.db.ExecContext(ctx, lockUser, username)
+	return err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, create_at, role
+`
+
+type UpdateUserRoleParams struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserRole, arg.Username, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreateAt,
+		&i.Role,
 	)
 	return i, err
 }
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliveries(t *testing.T) {
+	account := createRandomAccount(t)
+
+	subscription, err := testQueries.CreateWebhookSubscription(context.Background(), CreateWebhookSubscriptionParams{
+		Username:   account.Owner,
+		Url:        "https://example.com/hooks",
+		Secret:     "secret",
+		EventTypes: []string{EventAccountCreated, EventTransferCreated},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{EventAccountCreated, EventTransferCreated}, subscription.EventTypes)
+
+	event, err := testQueries.CreateOutboxEvent(context.Background(), CreateOutboxEventParams{
+		AggregateType: AggregateAccount,
+		AggregateID:   "1",
+		EventType:     EventAccountCreated,
+		Payload:       []byte(`{}`),
+	})
+	require.NoError(t, err)
+
+	arg := CreateWebhookDeliveriesParams{
+		EventID:   event.ID,
+		Usernames: []string{account.Owner},
+		EventType: EventAccountCreated,
+	}
+
+	n, err := testQueries.CreateWebhookDeliveries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	// an event published again is not delivered twice
+	n, err = testQueries.CreateWebhookDeliveries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	// nor is an event the subscription did not ask for
+	arg.EventType = EventAccountBalanceUpdated
+	n, err = testQueries.CreateWebhookDeliveries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	claimed, err := testQueries.ClaimDueWebhookDeliveries(context.Background(), ClaimDueWebhookDeliveriesParams{
+		LeaseSeconds: 60,
+		PageSize:     1000,
+	})
+	require.NoError(t, err)
+
+	var delivery WebhookDelivery
+	for _, d := range claimed {
+		if d.SubscriptionID == subscription.ID {
+			delivery = d
+		}
+	}
+	require.NotZero(t, delivery.ID)
+	require.WithinDuration(t, time.Now().Add(time.Minute), delivery.NextAttemptAt, 5*time.Second)
+
+	status := int32(500)
+	delivery, err = testQueries.RecordWebhookDeliveryAttempt(context.Background(), RecordWebhookDeliveryAttemptParams{
+		ID:             delivery.ID,
+		Status:         WebhookDeliveryStatusFailed,
+		NextAttemptAt:  time.Now(),
+		ResponseStatus: &status,
+		LastError:      "receiver responded with 500",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), delivery.Attempts)
+	require.Nil(t, delivery.DeliveredAt)
+
+	delivery, err = testQueries.ReplayWebhookDelivery(context.Background(), delivery.ID)
+	require.NoError(t, err)
+	require.Equal(t, WebhookDeliveryStatusPending, delivery.Status)
+	require.Zero(t, delivery.Attempts)
+
+	delivery, err = testQueries.RecordWebhookDeliveryAttempt(context.Background(), RecordWebhookDeliveryAttemptParams{
+		ID:            delivery.ID,
+		Status:        WebhookDeliveryStatusSucceeded,
+		NextAttemptAt: time.Now(),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, delivery.DeliveredAt)
+
+	// deleting the subscription takes its delivery log with it
+	err = testQueries.DeleteWebhookSubscription(context.Background(), subscription.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetWebhookDelivery(context.Background(), delivery.ID)
+	require.Error(t, err)
+}
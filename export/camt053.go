@@ -0,0 +1,180 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"simple_bank/currency"
+	"simple_bank/statement"
+	"strconv"
+	"time"
+)
+
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"
+
+type camtDocument struct {
+	XMLName   xml.Name      `xml:"Document"`
+	Namespace string        `xml:"xmlns,attr"`
+	Header    camtGroup     `xml:"BkToCstmrStmt>GrpHdr"`
+	Statement camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtGroup struct {
+	MessageID string `xml:"MsgId"`
+	CreatedAt string `xml:"CreDtTm"`
+}
+
+type camtStatement struct {
+	ID        string        `xml:"Id"`
+	CreatedAt string        `xml:"CreDtTm"`
+	From      string        `xml:"FrToDt>FrDtTm"`
+	To        string        `xml:"FrToDt>ToDtTm"`
+	AccountID string        `xml:"Acct>Id>Othr>Id"`
+	Currency  string        `xml:"Acct>Ccy"`
+	Owner     string        `xml:"Acct>Ownr>Nm"`
+	Balances  []camtBalance `xml:"Bal"`
+	Entries   []camtEntry   `xml:"Ntry"`
+}
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtBalance struct {
+	Type      string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount    camtAmount `xml:"Amt"`
+	Indicator string     `xml:"CdtDbtInd"`
+	Date      string     `xml:"Dt>DtTm"`
+}
+
+type camtEntry struct {
+	Reference   string                  `xml:"NtryRef"`
+	Amount      camtAmount              `xml:"Amt"`
+	Indicator   string                  `xml:"CdtDbtInd"`
+	Status      string                  `xml:"Sts>Cd"`
+	BookingDate string                  `xml:"BookgDt>DtTm"`
+	ServicerRef string                  `xml:"AcctSvcrRef"`
+	BankTxCode  string                  `xml:"BkTxCd>Prtry>Cd"`
+	Details     *camtTransactionDetails `xml:"NtryDtls>TxDtls,omitempty"`
+}
+
+type camtTransactionDetails struct {
+	TransactionID   string          `xml:"Refs>TxId"`
+	DebtorAccount   *camtAccountRef `xml:"RltdPties>DbtrAcct,omitempty"`
+	CreditorAccount *camtAccountRef `xml:"RltdPties>CdtrAcct,omitempty"`
+}
+
+type camtAccountRef struct {
+	ID string `xml:"Id>Othr>Id"`
+}
+
+// Camt053Writer writes statements as ISO 20022 camt.053 bank-to-customer statements
+type Camt053Writer struct{}
+
+func (writer *Camt053Writer) ContentType() string {
+	return "application/vnd.iso20022.camt.053+xml"
+}
+
+func (writer *Camt053Writer) FileExtension() string {
+	return "xml"
+}
+
+func (writer *Camt053Writer) Write(w io.Writer, s statement.Statement) error {
+	cur, err := currency.Lookup(s.Currency)
+	if err != nil {
+		return err
+	}
+
+	id := fmt.Sprintf("%d-%s", s.AccountID, s.From.UTC().Format("20060102"))
+	createdAt := camtTime(s.GeneratedAt)
+
+	doc := camtDocument{
+		Namespace: camt053Namespace,
+		Header: camtGroup{
+			MessageID: id,
+			CreatedAt: createdAt,
+		},
+		Statement: camtStatement{
+			ID:        id,
+			CreatedAt: createdAt,
+			From:      camtTime(s.From),
+			To:        camtTime(s.To),
+			AccountID: strconv.FormatInt(s.AccountID, 10),
+			Currency:  s.Currency,
+			Owner:     s.Owner,
+			Balances: []camtBalance{
+				newCamtBalance("OPBD", currency.New(s.OpeningBalance, cur), s.From),
+				newCamtBalance("CLBD", currency.New(s.ClosingBalance, cur), s.To),
+			},
+			Entries: make([]camtEntry, 0, len(s.Lines)),
+		},
+	}
+
+	for _, line := range s.Lines {
+		entry := camtEntry{
+			Reference:   strconv.FormatInt(line.EntryID, 10),
+			Amount:      newCamtAmount(currency.New(line.Amount, cur)),
+			Indicator:   creditDebitIndicator(line.Amount),
+			Status:      "BOOK",
+			BookingDate: camtTime(line.CreatedAt),
+			ServicerRef: strconv.FormatInt(line.EntryID, 10),
+			BankTxCode:  "ENTRY",
+		}
+
+		if line.TransferID != nil {
+			entry.BankTxCode = "TRANSFER"
+			entry.Details = &camtTransactionDetails{TransactionID: strconv.FormatInt(*line.TransferID, 10)}
+
+			if line.CounterpartyAccountID != nil {
+				counterparty := &camtAccountRef{ID: strconv.FormatInt(*line.CounterpartyAccountID, 10)}
+				if line.Amount < 0 {
+					entry.Details.CreditorAccount = counterparty
+				} else {
+					entry.Details.DebtorAccount = counterparty
+				}
+			}
+		}
+
+		doc.Statement.Entries = append(doc.Statement.Entries, entry)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+func newCamtBalance(code string, amount currency.Money, at time.Time) camtBalance {
+	return camtBalance{
+		Type:      code,
+		Amount:    newCamtAmount(amount),
+		Indicator: creditDebitIndicator(amount.Amount),
+		Date:      camtTime(at),
+	}
+}
+
+// newCamtAmount writes the amount unsigned, in major units. Its sign goes in the CdtDbtInd next to it.
+func newCamtAmount(amount currency.Money) camtAmount {
+	unsigned := currency.New(abs(amount.Amount), amount.Currency)
+	return camtAmount{Currency: amount.Currency.Code, Value: unsigned.Decimal()}
+}
+
+func creditDebitIndicator(amount int64) string {
+	if amount < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+func camtTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
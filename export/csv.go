@@ -0,0 +1,61 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"simple_bank/currency"
+	"simple_bank/statement"
+	"strconv"
+	"time"
+)
+
+var csvHeader = []string{"entry_id", "created_at", "transfer_id", "counterparty_account_id", "amount", "balance"}
+
+// CSVWriter writes one row per statement line, preceded by a header row. Amounts are in major units,
+// as spreadsheets expect.
+type CSVWriter struct{}
+
+func (writer *CSVWriter) ContentType() string {
+	return "text/csv"
+}
+
+func (writer *CSVWriter) FileExtension() string {
+	return "csv"
+}
+
+func (writer *CSVWriter) Write(w io.Writer, s statement.Statement) error {
+	cur, err := currency.Lookup(s.Currency)
+	if err != nil {
+		return err
+	}
+
+	out := csv.NewWriter(w)
+
+	if err := out.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, line := range s.Lines {
+		record := []string{
+			strconv.FormatInt(line.EntryID, 10),
+			line.CreatedAt.UTC().Format(time.RFC3339),
+			optionalID(line.TransferID),
+			optionalID(line.CounterpartyAccountID),
+			currency.New(line.Amount, cur).Decimal(),
+			currency.New(line.Balance, cur).Decimal(),
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+func optionalID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
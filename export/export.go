@@ -0,0 +1,52 @@
+package export
+
+import (
+	"errors"
+	"io"
+	"simple_bank/statement"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatOFX     Format = "ofx"
+	FormatCamt053 Format = "camt053"
+)
+
+// Writer encodes a statement in a file format other tools can import
+type Writer interface {
+	// ContentType returns the media type of the encoded statement
+	ContentType() string
+	// FileExtension returns the extension used when the statement is downloaded
+	FileExtension() string
+	Write(w io.Writer, s statement.Statement) error
+}
+
+func NewWriter(format Format) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &CSVWriter{}, nil
+	case FormatOFX:
+		return &OFXWriter{}, nil
+	case FormatCamt053:
+		return &Camt053Writer{}, nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// MediaTypes maps the media types accepted by the statement endpoint to their export format
+var MediaTypes = map[string]Format{
+	"text/csv":                              FormatCSV,
+	"application/x-ofx":                     FormatOFX,
+	"application/vnd.iso20022.camt.053+xml": FormatCamt053,
+}
+
+func abs(amount int64) int64 {
+	if amount < 0 {
+		return -amount
+	}
+	return amount
+}
@@ -0,0 +1,149 @@
+package export
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"simple_bank/currency"
+	"simple_bank/statement"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func testStatement() statement.Statement {
+	from := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	transferID := int64(7)
+	counterparty := int64(2)
+
+	return statement.Statement{
+		AccountID:      1,
+		Owner:          "alice",
+		Currency:       "EUR",
+		From:           from,
+		To:             from.AddDate(0, 1, 0),
+		OpeningBalance: 100,
+		Lines: []statement.Line{
+			{
+				EntryID:   10,
+				Amount:    50,
+				Balance:   150,
+				CreatedAt: from.Add(9 * time.Hour),
+			},
+			{
+				EntryID:               11,
+				TransferID:            &transferID,
+				CounterpartyAccountID: &counterparty,
+				Amount:                -170,
+				Balance:               -20,
+				CreatedAt:             from.Add(30 * time.Hour),
+			},
+		},
+		ClosingBalance: -20,
+		Reconciled:     true,
+		GeneratedAt:    time.Date(2022, 7, 1, 8, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestWriters(t *testing.T) {
+	testCases := []struct {
+		format      Format
+		contentType string
+		golden      string
+	}{
+		{format: FormatCSV, contentType: "text/csv", golden: "statement.csv"},
+		{format: FormatOFX, contentType: "application/x-ofx", golden: "statement.ofx"},
+		{format: FormatCamt053, contentType: "application/vnd.iso20022.camt.053+xml", golden: "statement.camt053.xml"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(string(tc.format), func(t *testing.T) {
+			writer, err := NewWriter(tc.format)
+			require.NoError(t, err)
+			require.Equal(t, tc.contentType, writer.ContentType())
+			require.Equal(t, tc.format, MediaTypes[writer.ContentType()])
+
+			var buf bytes.Buffer
+			err = writer.Write(&buf, testStatement())
+			require.NoError(t, err)
+
+			golden := filepath.Join("testdata", tc.golden)
+			if *update {
+				err = os.WriteFile(golden, buf.Bytes(), 0644)
+				require.NoError(t, err)
+			}
+
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			require.Equal(t, string(want), buf.String())
+		})
+	}
+}
+
+func TestWritersEmptyStatement(t *testing.T) {
+	s := testStatement()
+	s.Lines = []statement.Line{}
+
+	for _, format := range []Format{FormatCSV, FormatOFX, FormatCamt053} {
+		writer, err := NewWriter(format)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = writer.Write(&buf, s)
+		require.NoError(t, err)
+		require.NotEmpty(t, buf.String())
+	}
+}
+
+func TestNewWriterUnsupportedFormat(t *testing.T) {
+	writer, err := NewWriter("pdf")
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+	require.Nil(t, writer)
+}
+
+func TestWritersMinorUnits(t *testing.T) {
+	testCases := []struct {
+		currency string
+		csv      string
+		ofx      string
+		camt     string
+	}{
+		{currency: "EUR", csv: ",-1.70,-0.20\n", ofx: "<TRNAMT>-1.70</TRNAMT>", camt: `<Amt Ccy="EUR">1.70</Amt>`},
+		{currency: "KRW", csv: ",-170,-20\n", ofx: "<TRNAMT>-170</TRNAMT>", camt: `<Amt Ccy="KRW">170</Amt>`},
+		{currency: "KWD", csv: ",-0.170,-0.020\n", ofx: "<TRNAMT>-0.170</TRNAMT>", camt: `<Amt Ccy="KWD">0.170</Amt>`},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.currency, func(t *testing.T) {
+			s := testStatement()
+			s.Currency = tc.currency
+
+			var csv, ofx, camt bytes.Buffer
+			require.NoError(t, (&CSVWriter{}).Write(&csv, s))
+			require.NoError(t, (&OFXWriter{}).Write(&ofx, s))
+			require.NoError(t, (&Camt053Writer{}).Write(&camt, s))
+
+			require.Contains(t, csv.String(), tc.csv)
+			require.Contains(t, ofx.String(), tc.ofx)
+			require.Contains(t, camt.String(), tc.camt)
+		})
+	}
+}
+
+func TestWritersUnknownCurrency(t *testing.T) {
+	s := testStatement()
+	s.Currency = "XYZ"
+
+	for _, writer := range []Writer{&CSVWriter{}, &OFXWriter{}, &Camt053Writer{}} {
+		err := writer.Write(&bytes.Buffer{}, s)
+		require.ErrorIs(t, err, currency.ErrUnknownCurrency)
+	}
+}
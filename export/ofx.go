@@ -0,0 +1,140 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"simple_bank/currency"
+	"simple_bank/statement"
+	"strconv"
+	"time"
+)
+
+const (
+	ofxHeader     = `<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>`
+	ofxTimeLayout = "20060102150405"
+	ofxBankID     = "SIMPLEBANK"
+)
+
+type ofxDocument struct {
+	XMLName xml.Name             `xml:"OFX"`
+	SignOn  ofxSignOn            `xml:"SIGNONMSGSRSV1>SONRS"`
+	Bank    ofxStatementResponse `xml:"BANKMSGSRSV1>STMTTRNRS"`
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxSignOn struct {
+	Status   ofxStatus `xml:"STATUS"`
+	DTServer string    `xml:"DTSERVER"`
+	Language string    `xml:"LANGUAGE"`
+}
+
+type ofxStatementResponse struct {
+	TrnUID    string       `xml:"TRNUID"`
+	Status    ofxStatus    `xml:"STATUS"`
+	Statement ofxStatement `xml:"STMTRS"`
+}
+
+type ofxStatement struct {
+	Currency     string           `xml:"CURDEF"`
+	BankID       string           `xml:"BANKACCTFROM>BANKID"`
+	AccountID    string           `xml:"BANKACCTFROM>ACCTID"`
+	AccountType  string           `xml:"BANKACCTFROM>ACCTTYPE"`
+	Start        string           `xml:"BANKTRANLIST>DTSTART"`
+	End          string           `xml:"BANKTRANLIST>DTEND"`
+	Transactions []ofxTransaction `xml:"BANKTRANLIST>STMTTRN"`
+	Balance      string           `xml:"LEDGERBAL>BALAMT"`
+	BalanceAsOf  string           `xml:"LEDGERBAL>DTASOF"`
+}
+
+type ofxTransaction struct {
+	Type   string `xml:"TRNTYPE"`
+	Posted string `xml:"DTPOSTED"`
+	Amount string `xml:"TRNAMT"`
+	FitID  string `xml:"FITID"`
+	Name   string `xml:"NAME,omitempty"`
+	Memo   string `xml:"MEMO,omitempty"`
+}
+
+// OFXWriter writes statements as OFX 2.2 bank statement responses
+type OFXWriter struct{}
+
+func (writer *OFXWriter) ContentType() string {
+	return "application/x-ofx"
+}
+
+func (writer *OFXWriter) FileExtension() string {
+	return "ofx"
+}
+
+func (writer *OFXWriter) Write(w io.Writer, s statement.Statement) error {
+	cur, err := currency.Lookup(s.Currency)
+	if err != nil {
+		return err
+	}
+
+	ok := ofxStatus{Code: 0, Severity: "INFO"}
+
+	doc := ofxDocument{
+		SignOn: ofxSignOn{
+			Status:   ok,
+			DTServer: ofxTime(s.GeneratedAt),
+			Language: "ENG",
+		},
+		Bank: ofxStatementResponse{
+			TrnUID: "0",
+			Status: ok,
+			Statement: ofxStatement{
+				Currency:     s.Currency,
+				BankID:       ofxBankID,
+				AccountID:    strconv.FormatInt(s.AccountID, 10),
+				AccountType:  "CHECKING",
+				Start:        ofxTime(s.From),
+				End:          ofxTime(s.To),
+				Transactions: make([]ofxTransaction, 0, len(s.Lines)),
+				Balance:      currency.New(s.ClosingBalance, cur).Decimal(),
+				BalanceAsOf:  ofxTime(s.To),
+			},
+		},
+	}
+
+	for _, line := range s.Lines {
+		trn := ofxTransaction{
+			Type:   "CREDIT",
+			Posted: ofxTime(line.CreatedAt),
+			Amount: currency.New(line.Amount, cur).Decimal(),
+			FitID:  strconv.FormatInt(line.EntryID, 10),
+		}
+		if line.Amount < 0 {
+			trn.Type = "DEBIT"
+		}
+		if line.CounterpartyAccountID != nil {
+			trn.Name = fmt.Sprintf("Account %d", *line.CounterpartyAccountID)
+		}
+		if line.TransferID != nil {
+			trn.Memo = fmt.Sprintf("Transfer %d", *line.TransferID)
+		}
+		doc.Bank.Statement.Transactions = append(doc.Bank.Statement.Transactions, trn)
+	}
+
+	if _, err := io.WriteString(w, xml.Header+ofxHeader+"\n"); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+func ofxTime(t time.Time) string {
+	return t.UTC().Format(ofxTimeLayout) + "[0:GMT]"
+}
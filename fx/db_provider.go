@@ -0,0 +1,38 @@
+package fx
+
+import (
+	"context"
+	"database/sql"
+	db "simple_bank/db/sqlc"
+	"time"
+)
+
+// DBProvider reads rates from the exchange_rates table
+type DBProvider struct {
+	store db.Store
+}
+
+func NewDBProvider(store db.Store) *DBProvider {
+	return &DBProvider{store: store}
+}
+
+func (provider *DBProvider) GetRate(ctx context.Context, from string, to string, at time.Time) (Rate, error) {
+	exchangeRate, err := provider.store.GetExchangeRate(ctx, db.GetExchangeRateParams{
+		BaseCurrency:  from,
+		QuoteCurrency: to,
+		At:            at,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Rate{}, ErrRateNotFound
+		}
+		return Rate{}, err
+	}
+
+	return Rate{
+		From:        exchangeRate.BaseCurrency,
+		To:          exchangeRate.QuoteCurrency,
+		Value:       exchangeRate.Rate,
+		EffectiveAt: exchangeRate.EffectiveAt,
+	}, nil
+}
@@ -0,0 +1,63 @@
+package fx
+
+import (
+	"context"
+	"database/sql"
+	mockdb "simple_bank/db/mock"
+	db "simple_bank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	provider := NewDBProvider(store)
+
+	at := time.Now()
+	exchangeRate := db.ExchangeRate{
+		ID:            1,
+		BaseCurrency:  "USD",
+		QuoteCurrency: "KRW",
+		Rate:          "1300.5000000000",
+		EffectiveAt:   at.Add(-time.Hour),
+	}
+
+	arg := db.GetExchangeRateParams{
+		BaseCurrency:  "USD",
+		QuoteCurrency: "KRW",
+		At:            at,
+	}
+	store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(arg)).Times(1).Return(exchangeRate, nil)
+
+	rate, err := provider.GetRate(context.Background(), "USD", "KRW", at)
+	require.NoError(t, err)
+	require.Equal(t, "USD", rate.From)
+	require.Equal(t, "KRW", rate.To)
+	require.Equal(t, exchangeRate.Rate, rate.Value)
+	require.Equal(t, exchangeRate.EffectiveAt, rate.EffectiveAt)
+}
+
+func TestDBProviderErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	provider := NewDBProvider(store)
+
+	gomock.InOrder(
+		store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(1).Return(db.ExchangeRate{}, sql.ErrNoRows),
+		store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(1).Return(db.ExchangeRate{}, sql.ErrConnDone),
+	)
+
+	_, err := provider.GetRate(context.Background(), "USD", "KRW", time.Now())
+	require.ErrorIs(t, err, ErrRateNotFound)
+
+	_, err = provider.GetRate(context.Background(), "USD", "KRW", time.Now())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
@@ -0,0 +1,32 @@
+package fx
+
+import (
+	"context"
+	"fmt"
+	db "simple_bank/db/sqlc"
+	"time"
+)
+
+// NewTransferExchange converts amount from one currency to another at the rate the provider currently has in effect
+func NewTransferExchange(ctx context.Context, provider RateProvider, from string, to string, amount int64) (*db.TransferExchange, error) {
+	rate, err := provider.GetRate(ctx, from, to, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	toAmount, err := rate.Convert(amount)
+	if err != nil {
+		return nil, err
+	}
+	// debiting the sender without crediting the recipient would make the money disappear
+	if toAmount <= 0 {
+		return nil, fmt.Errorf("%w: %d %s", ErrAmountTooSmall, amount, from)
+	}
+
+	return &db.TransferExchange{
+		FromCurrency: from,
+		ToCurrency:   to,
+		Rate:         rate.Value,
+		ToAmount:     toAmount,
+	}, nil
+}
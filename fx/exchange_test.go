@@ -0,0 +1,30 @@
+package fx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransferExchange(t *testing.T) {
+	provider, err := NewStaticProvider(
+		Rate{From: "USD", To: "KRW", Value: "1300"},
+		Rate{From: "KRW", To: "USD", Value: "0.0007692"},
+	)
+	require.NoError(t, err)
+
+	exchange, err := NewTransferExchange(context.Background(), provider, "USD", "KRW", 1000)
+	require.NoError(t, err)
+	require.Equal(t, "USD", exchange.FromCurrency)
+	require.Equal(t, "KRW", exchange.ToCurrency)
+	require.Equal(t, "1300", exchange.Rate)
+	require.Equal(t, int64(13000), exchange.ToAmount)
+
+	// 5 won is a fraction of a cent
+	_, err = NewTransferExchange(context.Background(), provider, "KRW", "USD", 5)
+	require.ErrorIs(t, err, ErrAmountTooSmall)
+
+	_, err = NewTransferExchange(context.Background(), provider, "EUR", "USD", 100)
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
@@ -0,0 +1,28 @@
+package fx
+
+import (
+	"fmt"
+	db "simple_bank/db/sqlc"
+	"simple_bank/util"
+)
+
+const (
+	ProviderTypeDB     = "db"
+	ProviderTypeStatic = "static"
+)
+
+// NewRateProvider creates the rate provider selected by config.FXRateProvider, defaulting to the database
+func NewRateProvider(config util.Config, store db.Store) (RateProvider, error) {
+	switch config.FXRateProvider {
+	case "", ProviderTypeDB:
+		return NewDBProvider(store), nil
+	case ProviderTypeStatic:
+		provider, err := NewStaticProviderFromFile(config.FXRatesFile)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	default:
+		return nil, fmt.Errorf("unsupported rate provider: %s", config.FXRateProvider)
+	}
+}
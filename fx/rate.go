@@ -0,0 +1,77 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"simple_bank/currency"
+	"time"
+)
+
+var (
+	ErrRateNotFound = errors.New("exchange rate not found")
+	ErrInvalidRate  = errors.New("exchange rate must be a positive decimal")
+	// ErrAmountTooSmall is returned when an amount converts to less than one minor unit of the target currency
+	ErrAmountTooSmall = errors.New("amount is too small to convert")
+)
+
+// Rate is the number of major units of To that one major unit of From buys, such as 1300.5 won per dollar
+type Rate struct {
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	Value       string    `json:"rate"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+// RateProvider looks up the exchange rate in effect at the given time
+type RateProvider interface {
+	GetRate(ctx context.Context, from string, to string, at time.Time) (Rate, error)
+}
+
+// Convert applies the rate to an amount in minor units of the source currency and returns minor units of
+// the target currency, rounding half away from zero. The rate itself is quoted in major units.
+func (rate Rate) Convert(amount int64) (int64, error) {
+	value, err := parseRate(rate.Value)
+	if err != nil {
+		return 0, err
+	}
+
+	from, err := currency.Lookup(rate.From)
+	if err != nil {
+		return 0, err
+	}
+	to, err := currency.Lookup(rate.To)
+	if err != nil {
+		return 0, err
+	}
+
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), value)
+	converted.Mul(converted, currency.MinorUnitScale(from, to))
+
+	rounded := roundRat(converted)
+	if !rounded.IsInt64() {
+		return 0, fmt.Errorf("converted amount of %d %s overflows", amount, rate.To)
+	}
+
+	return rounded.Int64(), nil
+}
+
+func parseRate(value string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok || rate.Sign() <= 0 {
+		return nil, ErrInvalidRate
+	}
+	return rate, nil
+}
+
+func roundRat(x *big.Rat) *big.Int {
+	// add or subtract one half before truncating towards zero
+	half := big.NewRat(1, 2)
+	if x.Sign() < 0 {
+		half.Neg(half)
+	}
+
+	shifted := new(big.Rat).Add(x, half)
+	return new(big.Int).Quo(shifted.Num(), shifted.Denom())
+}
@@ -0,0 +1,64 @@
+package fx
+
+import (
+	"math"
+	"simple_bank/currency"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	testCases := []struct {
+		name     string
+		from     string
+		to       string
+		rate     string
+		amount   int64
+		expected int64
+	}{
+		{name: "Identity", from: "USD", to: "USD", rate: "1", amount: 100, expected: 100},
+		{name: "SameMinorUnits", from: "USD", to: "EUR", rate: "0.9", amount: 1000, expected: 900},
+		{name: "RoundHalfUp", from: "USD", to: "EUR", rate: "0.5", amount: 5, expected: 3},
+		{name: "RoundDown", from: "USD", to: "EUR", rate: "0.9", amount: 11, expected: 10},
+		{name: "Negative", from: "USD", to: "EUR", rate: "0.5", amount: -5, expected: -3},
+		// 10 cents buy 130.05 won
+		{name: "USDToKRW", from: "USD", to: "KRW", rate: "1300.5", amount: 10, expected: 130},
+		// $10.00 buy 13,002.5 won
+		{name: "USDToKRWRoundHalfUp", from: "USD", to: "KRW", rate: "1300.25", amount: 1000, expected: 13003},
+		// 13,000 won buy $9.9996
+		{name: "KRWToUSD", from: "KRW", to: "USD", rate: "0.0007692", amount: 13000, expected: 1000},
+		{name: "KRWToUSDSmallAmount", from: "KRW", to: "USD", rate: "0.0007692", amount: 1, expected: 0},
+		// 1.000 dinar buys $3.25
+		{name: "KWDToUSD", from: "KWD", to: "USD", rate: "3.25", amount: 1000, expected: 325},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			rate := Rate{From: tc.from, To: tc.to, Value: tc.rate}
+
+			converted, err := rate.Convert(tc.amount)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, converted)
+		})
+	}
+}
+
+func TestConvertInvalidRate(t *testing.T) {
+	for _, value := range []string{"", "abc", "0", "-1.5"} {
+		_, err := Rate{Value: value}.Convert(100)
+		require.ErrorIs(t, err, ErrInvalidRate)
+	}
+}
+
+func TestConvertUnknownCurrency(t *testing.T) {
+	_, err := Rate{From: "USD", To: "XYZ", Value: "1"}.Convert(100)
+	require.ErrorIs(t, err, currency.ErrUnknownCurrency)
+}
+
+func TestConvertOverflow(t *testing.T) {
+	_, err := Rate{From: "USD", To: "KRW", Value: "1300"}.Convert(math.MaxInt64)
+	require.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// StaticProvider serves a fixed set of rates, e.g. loaded from a JSON file in tests or local setups
+type StaticProvider struct {
+	rates map[string]Rate
+}
+
+func NewStaticProvider(rates ...Rate) (*StaticProvider, error) {
+	provider := &StaticProvider{
+		rates: make(map[string]Rate, len(rates)),
+	}
+
+	for _, rate := range rates {
+		if _, err := parseRate(rate.Value); err != nil {
+			return nil, fmt.Errorf("invalid rate %s/%s: %w", rate.From, rate.To, err)
+		}
+		provider.rates[rateKey(rate.From, rate.To)] = rate
+	}
+
+	return provider, nil
+}
+
+// NewStaticProviderFromFile loads rates from a JSON file of the form
+// {"rates": [{"from": "USD", "to": "KRW", "rate": "1300.25"}]}
+func NewStaticProviderFromFile(file string) (*StaticProvider, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read rates file: %w", err)
+	}
+
+	var content struct {
+		Rates []Rate `json:"rates"`
+	}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("cannot parse rates file: %w", err)
+	}
+
+	return NewStaticProvider(content.Rates...)
+}
+
+func (provider *StaticProvider) GetRate(ctx context.Context, from string, to string, at time.Time) (Rate, error) {
+	rate, ok := provider.rates[rateKey(from, to)]
+	if !ok || rate.EffectiveAt.After(at) {
+		return Rate{}, ErrRateNotFound
+	}
+	return rate, nil
+}
+
+func rateKey(from string, to string) string {
+	return from + "/" + to
+}
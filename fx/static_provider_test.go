@@ -0,0 +1,58 @@
+package fx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticProvider(t *testing.T) {
+	provider, err := NewStaticProvider(
+		Rate{From: "USD", To: "KRW", Value: "1300"},
+		Rate{From: "EUR", To: "USD", Value: "1.1", EffectiveAt: time.Now().Add(time.Hour)},
+	)
+	require.NoError(t, err)
+
+	rate, err := provider.GetRate(context.Background(), "USD", "KRW", time.Now())
+	require.NoError(t, err)
+	require.Equal(t, "1300", rate.Value)
+
+	// rates are directional
+	_, err = provider.GetRate(context.Background(), "KRW", "USD", time.Now())
+	require.ErrorIs(t, err, ErrRateNotFound)
+
+	// not in effect yet
+	_, err = provider.GetRate(context.Background(), "EUR", "USD", time.Now())
+	require.ErrorIs(t, err, ErrRateNotFound)
+
+	_, err = NewStaticProvider(Rate{From: "USD", To: "KRW", Value: "0"})
+	require.Error(t, err)
+}
+
+func TestStaticProviderFromFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rates.json")
+	err := os.WriteFile(file, []byte(`{"rates": [{"from": "USD", "to": "EUR", "rate": "0.92"}]}`), 0600)
+	require.NoError(t, err)
+
+	provider, err := NewStaticProviderFromFile(file)
+	require.NoError(t, err)
+
+	rate, err := provider.GetRate(context.Background(), "USD", "EUR", time.Now())
+	require.NoError(t, err)
+	require.Equal(t, "USD", rate.From)
+	require.Equal(t, "EUR", rate.To)
+	require.Equal(t, "0.92", rate.Value)
+
+	_, err = NewStaticProviderFromFile(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+
+	err = os.WriteFile(file, []byte(`{invalid`), 0600)
+	require.NoError(t, err)
+
+	_, err = NewStaticProviderFromFile(file)
+	require.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	db "simple_bank/db/sqlc"
+	"simple_bank/pb"
+	"simple_bank/token"
+	"simple_bank/util"
+
+	"github.com/lib/pq"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	err := server.validateFields(field{"currency", req.GetCurrency(), "required,currency"})
+	if err != nil {
+		return nil, err
+	}
+
+	payload := authPayload(ctx)
+
+	account, err := server.store.CreateAccountTx(ctx, db.CreateAccountParams{
+		Owner:    payload.Username,
+		Currency: req.GetCurrency(),
+		Balance:  0,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation", "unique_violation":
+				return nil, status.Errorf(codes.AlreadyExists, "cannot create account: %s", err)
+			}
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create account: %s", err)
+	}
+
+	return &pb.CreateAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (server *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	err := server.validateFields(field{"id", req.GetId(), "required,min=1"})
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := server.store.GetAccount(ctx, req.GetId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	payload := authPayload(ctx)
+	if payload.Username != account.Owner && !isStaff(payload) {
+		return nil, status.Errorf(codes.PermissionDenied, "account doesn't belong to the authenticated user")
+	}
+
+	held, err := server.store.GetHeldAmount(ctx, account.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get held amount: %s", err)
+	}
+
+	rsp := convertAccount(account)
+	rsp.AvailableBalance = account.Balance - held
+	return &pb.GetAccountResponse{Account: rsp}, nil
+}
+
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	err := server.validateFields(
+		field{"page_id", req.GetPageId(), "required,min=1"},
+		field{"page_size", req.GetPageSize(), "required,min=5,max=10"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := server.store.ListAccounts(ctx, db.ListAccountsParams{
+		Owner:  authPayload(ctx).Username,
+		Limit:  req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list accounts: %s", err)
+	}
+
+	rsp := &pb.ListAccountsResponse{Accounts: make([]*pb.Account, 0, len(accounts))}
+	for _, account := range accounts {
+		rsp.Accounts = append(rsp.Accounts, convertAccount(account))
+	}
+	return rsp, nil
+}
+
+// isStaff reports whether the payload belongs to a banker who may access any customer's accounts
+func isStaff(payload *token.Payload) bool {
+	return payload.Role == util.TellerRole || payload.Role == util.AdminRole
+}
@@ -0,0 +1,86 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"simple_bank/revocation"
+	"simple_bank/token"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+)
+
+type authorizationPayloadKey struct{}
+
+// publicMethods can be called without an access token
+var publicMethods = map[string]bool{
+	"/pb.SimpleBank/CreateUser": true,
+	"/pb.SimpleBank/LoginUser":  true,
+}
+
+// authInterceptor is the gRPC counterpart of the HTTP authMiddleware: it verifies the bearer token
+// in the authorization metadata and stores its payload in the context of the handler.
+func authInterceptor(tokenMaker token.Maker, revocations *revocation.List) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		payload, err := authorizeUser(ctx, tokenMaker, revocations)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		return handler(context.WithValue(ctx, authorizationPayloadKey{}, payload), req)
+	}
+}
+
+func authorizeUser(ctx context.Context, tokenMaker token.Maker, revocations *revocation.List) (*token.Payload, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("missing metadata")
+	}
+
+	values := md.Get(authorizationHeaderKey)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("authorization header is not provided")
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid authorization header format")
+	}
+
+	authorizationType := strings.ToLower(fields[0])
+	if authorizationType != authorizationTypeBearer {
+		return nil, fmt.Errorf("unsupported authorization type: %s", authorizationType)
+	}
+
+	payload, err := tokenMaker.VerifyToken(fields[1])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := payload.CheckType(token.TypeAccess); err != nil {
+		return nil, err
+	}
+
+	if revocations.IsRevoked(payload.ID) {
+		return nil, revocation.ErrRevokedToken
+	}
+
+	return payload, nil
+}
+
+// authPayload returns the payload stored by authInterceptor
+func authPayload(ctx context.Context) *token.Payload {
+	return ctx.Value(authorizationPayloadKey{}).(*token.Payload)
+}
@@ -0,0 +1,154 @@
+package gapi
+
+import (
+	"context"
+	mockdb "simple_bank/db/mock"
+	"simple_bank/revocation"
+	"simple_bank/token"
+	"simple_bank/util"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthInterceptor(t *testing.T) {
+	server := newTestServer(t, nil)
+	interceptor := authInterceptor(server.tokenMaker, server.revocations)
+	username := util.RandomOwner()
+
+	testCases := []struct {
+		name       string
+		method     string
+		setupCtx   func(t *testing.T) context.Context
+		checkError func(t *testing.T, payload *token.Payload, err error)
+	}{
+		{
+			name:   "OK",
+			method: "/pb.SimpleBank/GetAccount",
+			setupCtx: func(t *testing.T) context.Context {
+				return newContextWithBearerToken(t, server.tokenMaker, username, util.CustomerRole, time.Minute)
+			},
+			checkError: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, payload)
+				require.Equal(t, username, payload.Username)
+				require.Equal(t, util.CustomerRole, payload.Role)
+			},
+		},
+		{
+			name:   "PublicMethod",
+			method: "/pb.SimpleBank/LoginUser",
+			setupCtx: func(t *testing.T) context.Context {
+				return context.Background()
+			},
+			checkError: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.Nil(t, payload)
+			},
+		},
+		{
+			name:   "RefreshToken",
+			method: "/pb.SimpleBank/GetAccount",
+			setupCtx: func(t *testing.T) context.Context {
+				refreshToken, _, err := server.tokenMaker.CreateToken(username, util.CustomerRole, token.TypeRefresh, time.Minute)
+				require.NoError(t, err)
+
+				md := metadata.Pairs(authorizationHeaderKey, authorizationTypeBearer+" "+refreshToken)
+				return metadata.NewIncomingContext(context.Background(), md)
+			},
+			checkError: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "NoAuthorization",
+			method: "/pb.SimpleBank/GetAccount",
+			setupCtx: func(t *testing.T) context.Context {
+				return metadata.NewIncomingContext(context.Background(), metadata.MD{})
+			},
+			checkError: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "UnsupportedAuthorization",
+			method: "/pb.SimpleBank/GetAccount",
+			setupCtx: func(t *testing.T) context.Context {
+				md := metadata.Pairs(authorizationHeaderKey, "unsupported token")
+				return metadata.NewIncomingContext(context.Background(), md)
+			},
+			checkError: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "InvalidAuthorizationFormat",
+			method: "/pb.SimpleBank/GetAccount",
+			setupCtx: func(t *testing.T) context.Context {
+				md := metadata.Pairs(authorizationHeaderKey, authorizationTypeBearer)
+				return metadata.NewIncomingContext(context.Background(), md)
+			},
+			checkError: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "ExpiredToken",
+			method: "/pb.SimpleBank/GetAccount",
+			setupCtx: func(t *testing.T) context.Context {
+				return newContextWithBearerToken(t, server.tokenMaker, username, util.CustomerRole, -time.Minute)
+			},
+			checkError: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			var payload *token.Payload
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				payload, _ = ctx.Value(authorizationPayloadKey{}).(*token.Payload)
+				return nil, nil
+			}
+
+			_, err := interceptor(tc.setupCtx(t), nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+			tc.checkError(t, payload, err)
+		})
+	}
+}
+
+func TestAuthInterceptorSharedRevocations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CreateRevokedToken(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+
+	revocations := revocation.NewList(store)
+	server, err := NewServer(util.Config{TokenSymmetricKey: util.RandomString(32)}, store, revocations)
+	require.NoError(t, err)
+
+	ctx := newContextWithBearerToken(t, server.tokenMaker, util.RandomOwner(), util.CustomerRole, time.Minute)
+	payload, err := authorizeUser(ctx, server.tokenMaker, server.revocations)
+	require.NoError(t, err)
+
+	// the HTTP server revokes the token when its user logs out
+	err = revocations.Revoke(context.Background(), payload)
+	require.NoError(t, err)
+
+	interceptor := authInterceptor(server.tokenMaker, server.revocations)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/pb.SimpleBank/GetAccount"}, handler)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
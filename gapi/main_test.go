@@ -0,0 +1,47 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	db "simple_bank/db/sqlc"
+	"simple_bank/revocation"
+	"simple_bank/token"
+	"simple_bank/util"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := util.Config{
+		TokenSymmetricKey:    util.RandomString(32),
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Minute,
+	}
+
+	server, err := NewServer(config, store, revocation.NewList(store))
+	require.NoError(t, err)
+
+	return server
+}
+
+// newContextWithBearerToken returns an incoming context carrying an access token, as a client would send it
+func newContextWithBearerToken(t *testing.T, tokenMaker token.Maker, username string, role string, duration time.Duration) context.Context {
+	accessToken, _, err := tokenMaker.CreateToken(username, role, token.TypeAccess, duration)
+	require.NoError(t, err)
+
+	md := metadata.MD{
+		authorizationHeaderKey: []string{fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken)},
+	}
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+// newAuthorizedContext returns a context as authInterceptor hands it to the handlers
+func newAuthorizedContext(t *testing.T, username string, role string) context.Context {
+	payload, err := token.NewPayload(username, role, token.TypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	return context.WithValue(context.Background(), authorizationPayloadKey{}, payload)
+}
@@ -0,0 +1,31 @@
+package gapi
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const userAgentHeader = "user-agent"
+
+type clientMetadata struct {
+	UserAgent string
+	ClientIP  string
+}
+
+func extractMetadata(ctx context.Context) clientMetadata {
+	mtdt := clientMetadata{}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if userAgents := md.Get(userAgentHeader); len(userAgents) > 0 {
+			mtdt.UserAgent = userAgents[0]
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		mtdt.ClientIP = p.Addr.String()
+	}
+
+	return mtdt
+}
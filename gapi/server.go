@@ -0,0 +1,70 @@
+package gapi
+
+import (
+	"fmt"
+	"net"
+	"simple_bank/currency"
+	db "simple_bank/db/sqlc"
+	"simple_bank/fx"
+	"simple_bank/pb"
+	"simple_bank/revocation"
+	"simple_bank/token"
+	"simple_bank/util"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server serves gRPC requests for the banking service
+type Server struct {
+	pb.UnimplementedSimpleBankServer
+	config       util.Config
+	store        db.Store
+	tokenMaker   token.Maker
+	revocations  *revocation.List
+	rateProvider fx.RateProvider
+	validate     *validator.Validate
+}
+
+// NewServer creates the gRPC server, checking tokens against the revocation list of the HTTP server
+func NewServer(config util.Config, store db.Store, revocations *revocation.List) (*Server, error) {
+	tokenMaker, err := token.NewMaker(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	rateProvider, err := fx.NewRateProvider(config, store)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create rate provider: %w", err)
+	}
+
+	currencies, err := currency.NewRegistry(config.SupportedCurrencies)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create currency registry: %w", err)
+	}
+
+	server := &Server{
+		config:       config,
+		store:        store,
+		tokenMaker:   tokenMaker,
+		revocations:  revocations,
+		rateProvider: rateProvider,
+		validate:     newValidator(currencies),
+	}
+
+	return server, nil
+}
+
+func (server *Server) Start(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("cannot create listener: %w", err)
+	}
+
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(authInterceptor(server.tokenMaker, server.revocations)))
+	pb.RegisterSimpleBankServer(grpcServer, server)
+	reflection.Register(grpcServer)
+
+	return grpcServer.Serve(listener)
+}
@@ -0,0 +1,98 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	db "simple_bank/db/sqlc"
+	"simple_bank/fx"
+	"simple_bank/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateTransfer moves money between accounts. Transfers into an account of another currency are converted
+// at the current market rate; fx quotes and idempotency keys are only supported over HTTP.
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	err := server.validateFields(
+		field{"from_account_id", req.GetFromAccountId(), "required,min=1"},
+		field{"to_account_id", req.GetToAccountId(), "required,min=1"},
+		field{"amount", req.GetAmount(), "required,gt=0"},
+		field{"currency", req.GetCurrency(), "required,currency"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	fromAccount, err := server.getTransferAccount(ctx, req.GetFromAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", fromAccount.ID, fromAccount.Currency, req.GetCurrency())
+	}
+
+	if authPayload(ctx).Username != fromAccount.Owner {
+		return nil, status.Errorf(codes.PermissionDenied, "from account doesn't belong to the authenticated user")
+	}
+
+	toAccount, err := server.getTransferAccount(ctx, req.GetToAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	arg := db.TransferTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+	}
+
+	var result db.TransferTxResult
+	if toAccount.Currency != fromAccount.Currency {
+		exchange, err := fx.NewTransferExchange(ctx, server.rateProvider, fromAccount.Currency, toAccount.Currency, req.GetAmount())
+		if err != nil {
+			if errors.Is(err, fx.ErrRateNotFound) || errors.Is(err, fx.ErrAmountTooSmall) {
+				return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+			}
+			return nil, status.Errorf(codes.Internal, "failed to convert amount: %s", err)
+		}
+
+		result, err = server.store.CrossCurrencyTransferTx(ctx, db.CrossCurrencyTransferTxParams{
+			TransferTxParams: arg,
+			Exchange:         *exchange,
+		})
+	} else {
+		result, err = server.store.TransferTx(ctx, arg)
+	}
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		if errors.Is(err, db.ErrTransferLimitExceeded) {
+			return nil, status.Errorf(codes.ResourceExhausted, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create transfer: %s", err)
+	}
+
+	rsp := &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+	}
+	return rsp, nil
+}
+
+func (server *Server) getTransferAccount(ctx context.Context, accountID int64) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return account, status.Errorf(codes.NotFound, "account [%d] not found", accountID)
+		}
+		return account, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+	return account, nil
+}
@@ -0,0 +1,44 @@
+package gapi
+
+import (
+	"simple_bank/currency"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// validCurrency checks the field holds one of the configured currencies
+func validCurrency(currencies *currency.Registry) validator.Func {
+	return func(fieldLevel validator.FieldLevel) bool {
+		if code, ok := fieldLevel.Field().Interface().(string); ok {
+			return currencies.IsSupported(code)
+		}
+		return false
+	}
+}
+
+func newValidator(currencies *currency.Registry) *validator.Validate {
+	validate := validator.New()
+	if err := validate.RegisterValidation("currency", validCurrency(currencies)); err != nil {
+		panic(err)
+	}
+	return validate
+}
+
+// field pairs a request field with the validator tag it must satisfy, since generated messages carry no struct tags
+type field struct {
+	name  string
+	value interface{}
+	tag   string
+}
+
+// validateFields returns an InvalidArgument error naming the first field that fails validation
+func (server *Server) validateFields(fields ...field) error {
+	for _, f := range fields {
+		if err := server.validate.Var(f.value, f.tag); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid %s: %s", f.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package hold
+
+import (
+	"context"
+	"log"
+	db "simple_bank/db/sqlc"
+	"time"
+)
+
+// Expirer marks holds past their expiry as expired. Expired holds already stop counting against the
+// available balance when they expire; the job only keeps their status accurate.
+type Expirer struct {
+	store db.Store
+}
+
+func NewExpirer(store db.Store) *Expirer {
+	return &Expirer{store: store}
+}
+
+// Expire returns the number of holds it expired
+func (expirer *Expirer) Expire(ctx context.Context) (int64, error) {
+	return expirer.store.ExpireHolds(ctx)
+}
+
+// Run expires holds every interval until ctx is done
+func (expirer *Expirer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := expirer.Expire(ctx); err != nil {
+				log.Println("cannot expire holds: ", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package hold
+
+import (
+	"context"
+	"database/sql"
+	mockdb "simple_bank/db/mock"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpire(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expirer := NewExpirer(store)
+
+	store.EXPECT().ExpireHolds(gomock.Any()).Times(1).Return(int64(3), nil)
+
+	n, err := expirer.Expire(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(3), n)
+}
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expirer := NewExpirer(store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	// errors are logged and the job keeps going
+	gomock.InOrder(
+		store.EXPECT().ExpireHolds(gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone),
+		store.EXPECT().ExpireHolds(gomock.Any()).Times(1).DoAndReturn(func(_ context.Context) (int64, error) {
+			cancel()
+			return 1, nil
+		}),
+	)
+
+	go func() {
+		expirer.Run(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expirer did not stop")
+	}
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"simple_bank/api"
 	db "simple_bank/db/sqlc"
+	"simple_bank/gapi"
+	"simple_bank/revocation"
 	"simple_bank/util"
 
 	_ "github.com/lib/pq"
@@ -28,7 +31,17 @@ func main() {
 	}
 
 	store := db.NewStore(conn)
-	server, err := api.NewServer(config, store)
+
+	// both servers check and revoke tokens in the same list
+	revocations := revocation.NewList(store)
+	if err := revocations.Refresh(context.Background()); err != nil {
+		log.Fatal("cannot load revoked tokens: ", err)
+	}
+	go revocations.RunSweeper(context.Background(), config.RevocationSweepInterval)
+
+	go runGrpcServer(config, store, revocations)
+
+	server, err := api.NewServer(config, store, revocations)
 	if err != nil {
 		log.Fatal("cannot create server: ", err)
 	}
@@ -38,3 +51,16 @@ func main() {
 		log.Fatal("cannot connect to server: ", err)
 	}
 }
+
+func runGrpcServer(config util.Config, store db.Store, revocations *revocation.List) {
+	server, err := gapi.NewServer(config, store, revocations)
+	if err != nil {
+		log.Fatal("cannot create gRPC server: ", err)
+	}
+
+	log.Printf("start gRPC server at %s", config.GRPCServerAddress)
+	err = server.Start(config.GRPCServerAddress)
+	if err != nil {
+		log.Fatal("cannot start gRPC server: ", err)
+	}
+}
@@ -0,0 +1,70 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	db "simple_bank/db/sqlc"
+	"simple_bank/util"
+	"time"
+)
+
+const (
+	PublisherTypeStdout  = "stdout"
+	PublisherTypeFile    = "file"
+	PublisherTypeWebhook = "webhook"
+)
+
+// Message is what downstream services receive for each event. Delivery is at least once, so consumers
+// should skip IDs they have already seen.
+type Message struct {
+	ID            int64           `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+func NewMessage(event db.OutboxEvent) Message {
+	return Message{
+		ID:            event.ID,
+		Type:          event.EventType,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		OccurredAt:    event.CreatedAt,
+		Payload:       event.Payload,
+	}
+}
+
+// Publisher delivers messages to downstream services. Publish must not return until the message is delivered.
+type Publisher interface {
+	Publish(ctx context.Context, message Message) error
+}
+
+// MultiPublisher publishes each message to every publisher in turn. When one fails the message is published
+// to all of them again, so each must cope with duplicates the way any outbox consumer has to.
+type MultiPublisher []Publisher
+
+func (publishers MultiPublisher) Publish(ctx context.Context, message Message) error {
+	for _, publisher := range publishers {
+		if err := publisher.Publish(ctx, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewPublisher creates the publisher selected by config
+func NewPublisher(config util.Config) (Publisher, error) {
+	switch config.OutboxPublisher {
+	case "", PublisherTypeStdout:
+		return NewStdoutPublisher(), nil
+	case PublisherTypeFile:
+		return NewFilePublisher(config.OutboxFile)
+	case PublisherTypeWebhook:
+		return NewWebhookPublisher(config.OutboxWebhookURL)
+	default:
+		return nil, fmt.Errorf("unsupported outbox publisher: %s", config.OutboxPublisher)
+	}
+}
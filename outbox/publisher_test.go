@@ -0,0 +1,133 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	db "simple_bank/db/sqlc"
+	"simple_bank/util"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func randomMessage() Message {
+	return NewMessage(db.OutboxEvent{
+		ID:            util.RandomInt(1, 1000),
+		AggregateType: db.AggregateAccount,
+		AggregateID:   "42",
+		EventType:     db.EventAccountCreated,
+		Payload:       json.RawMessage(`{"id":42}`),
+		CreatedAt:     time.Now().UTC().Truncate(time.Second),
+	})
+}
+
+func TestWriterPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := NewWriterPublisher(&buf)
+
+	message1 := randomMessage()
+	message2 := randomMessage()
+	require.NoError(t, publisher.Publish(context.Background(), message1))
+	require.NoError(t, publisher.Publish(context.Background(), message2))
+
+	scanner := bufio.NewScanner(&buf)
+	for _, want := range []Message{message1, message2} {
+		require.True(t, scanner.Scan())
+
+		var got Message
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &got))
+		require.Equal(t, want.ID, got.ID)
+		require.Equal(t, want.Type, got.Type)
+		require.True(t, want.OccurredAt.Equal(got.OccurredAt))
+	}
+	require.False(t, scanner.Scan())
+}
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	publisher, err := NewFilePublisher(path)
+	require.NoError(t, err)
+
+	message := randomMessage()
+	require.NoError(t, publisher.Publish(context.Background(), message))
+	require.NoError(t, publisher.Close())
+
+	// a reopened file is appended to
+	publisher, err = NewFilePublisher(path)
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(context.Background(), message))
+	require.NoError(t, publisher.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 2, bytes.Count(data, []byte("\n")))
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	message := randomMessage()
+
+	testCases := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "OK", status: http.StatusOK},
+		{name: "Accepted", status: http.StatusAccepted},
+		{name: "ServerError", status: http.StatusInternalServerError, wantErr: true},
+		{name: "Redirect", status: http.StatusNotModified, wantErr: true},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodPost, r.Method)
+				require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				require.Equal(t, db.EventAccountCreated, r.Header.Get(EventTypeHeader))
+				require.NotEmpty(t, r.Header.Get(EventIDHeader))
+
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				var got Message
+				require.NoError(t, json.Unmarshal(body, &got))
+				require.Equal(t, message.ID, got.ID)
+
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			publisher, err := NewWebhookPublisher(server.URL)
+			require.NoError(t, err)
+
+			err = publisher.Publish(context.Background(), message)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewPublisher(t *testing.T) {
+	publisher, err := NewPublisher(util.Config{})
+	require.NoError(t, err)
+	require.IsType(t, &WriterPublisher{}, publisher)
+
+	_, err = NewPublisher(util.Config{OutboxPublisher: PublisherTypeWebhook})
+	require.Error(t, err)
+
+	_, err = NewPublisher(util.Config{OutboxPublisher: "kafka"})
+	require.Error(t, err)
+}
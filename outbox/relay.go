@@ -0,0 +1,56 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	db "simple_bank/db/sqlc"
+	"time"
+)
+
+const relayBatchSize = 100
+
+// Relay moves events from the outbox to a publisher in the order they were written
+type Relay struct {
+	store     db.Store
+	publisher Publisher
+}
+
+func NewRelay(store db.Store, publisher Publisher) *Relay {
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+	}
+}
+
+// RelayBatch publishes the oldest unpublished events and returns how many it published
+func (relay *Relay) RelayBatch(ctx context.Context) (int, error) {
+	return relay.store.RelayOutboxTx(ctx, db.RelayOutboxTxParams{
+		Limit: relayBatchSize,
+		Publish: func(ctx context.Context, event db.OutboxEvent) error {
+			return relay.publisher.Publish(ctx, NewMessage(event))
+		},
+	})
+}
+
+// Run relays events every interval until ctx is done. A full batch is followed straight away by the next.
+func (relay *Relay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				published, err := relay.RelayBatch(ctx)
+				if err != nil {
+					log.Println("cannot relay outbox events: ", err)
+				}
+				if err != nil || published < relayBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
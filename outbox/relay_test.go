@@ -0,0 +1,97 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	mockdb "simple_bank/db/mock"
+	db "simple_bank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPublisher struct {
+	messages []Message
+	failOn   int64
+}
+
+func (publisher *recordingPublisher) Publish(ctx context.Context, message Message) error {
+	if message.ID == publisher.failOn {
+		return errors.New("downstream unavailable")
+	}
+	publisher.messages = append(publisher.messages, message)
+	return nil
+}
+
+func randomEvents(n int) []db.OutboxEvent {
+	events := make([]db.OutboxEvent, n)
+	for i := range events {
+		events[i] = db.OutboxEvent{
+			ID:            int64(i + 1),
+			AggregateType: db.AggregateTransfer,
+			AggregateID:   "1",
+			EventType:     db.EventTransferCreated,
+			Payload:       json.RawMessage(`{"id":1}`),
+			CreatedAt:     time.Now(),
+		}
+	}
+	return events
+}
+
+// relayOutboxTx stands in for the store, handing events to the publisher callback like the real transaction
+func relayOutboxTx(events []db.OutboxEvent) func(ctx context.Context, arg db.RelayOutboxTxParams) (int, error) {
+	return func(ctx context.Context, arg db.RelayOutboxTxParams) (int, error) {
+		published := 0
+		for _, event := range events {
+			if err := arg.Publish(ctx, event); err != nil {
+				return published, err
+			}
+			published++
+		}
+		return published, nil
+	}
+}
+
+func TestRelayBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	events := randomEvents(3)
+	store := mockdb.NewMockStore(ctrl)
+	publisher := &recordingPublisher{}
+	relay := NewRelay(store, publisher)
+
+	store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(relayOutboxTx(events))
+
+	published, err := relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, published)
+
+	require.Len(t, publisher.messages, 3)
+	for i, message := range publisher.messages {
+		require.Equal(t, events[i].ID, message.ID)
+		require.Equal(t, db.EventTransferCreated, message.Type)
+		require.JSONEq(t, string(events[i].Payload), string(message.Payload))
+	}
+}
+
+func TestRelayBatchPublishError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	events := randomEvents(3)
+	store := mockdb.NewMockStore(ctrl)
+	publisher := &recordingPublisher{failOn: 2}
+	relay := NewRelay(store, publisher)
+
+	store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(relayOutboxTx(events))
+
+	// nothing after the failed event is published ahead of it
+	published, err := relay.RelayBatch(context.Background())
+	require.Error(t, err)
+	require.Equal(t, 1, published)
+	require.Len(t, publisher.messages, 1)
+}
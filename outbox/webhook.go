@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// Headers sent with every webhook so receivers can route and deduplicate without parsing the body
+const (
+	EventIDHeader   = "X-Event-ID"
+	EventTypeHeader = "X-Event-Type"
+)
+
+// WebhookPublisher POSTs each message as JSON to a URL. Any response other than 2xx is a failed delivery.
+type WebhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookPublisher(rawURL string) (*WebhookPublisher, error) {
+	if len(rawURL) == 0 {
+		return nil, errors.New("webhook url is required")
+	}
+
+	if _, err := url.ParseRequestURI(rawURL); err != nil {
+		return nil, fmt.Errorf("invalid webhook url: %w", err)
+	}
+
+	return &WebhookPublisher{
+		url:    rawURL,
+		client: &http.Client{Timeout: webhookTimeout},
+	}, nil
+}
+
+func (publisher *WebhookPublisher) Publish(ctx context.Context, message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, publisher.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventIDHeader, strconv.FormatInt(message.ID, 10))
+	request.Header.Set(EventTypeHeader, message.Type)
+
+	response, err := publisher.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", response.Status)
+	}
+	return nil
+}
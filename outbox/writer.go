@@ -0,0 +1,66 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterPublisher writes each message as a line of JSON
+type WriterPublisher struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterPublisher(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{w: w}
+}
+
+func NewStdoutPublisher() *WriterPublisher {
+	return NewWriterPublisher(os.Stdout)
+}
+
+func (publisher *WriterPublisher) Publish(ctx context.Context, message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	_, err = publisher.w.Write(append(data, '\n'))
+	return err
+}
+
+// FilePublisher appends messages to a file, one line of JSON each
+type FilePublisher struct {
+	*WriterPublisher
+	file *os.File
+}
+
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FilePublisher{
+		WriterPublisher: NewWriterPublisher(file),
+		file:            file,
+	}, nil
+}
+
+// Publish only returns once the message is synced to disk
+func (publisher *FilePublisher) Publish(ctx context.Context, message Message) error {
+	if err := publisher.WriterPublisher.Publish(ctx, message); err != nil {
+		return err
+	}
+	return publisher.file.Sync()
+}
+
+func (publisher *FilePublisher) Close() error {
+	return publisher.file.Close()
+}
@@ -0,0 +1,104 @@
+package revocation
+
+import (
+	"context"
+	"errors"
+	"log"
+	db "simple_bank/db/sqlc"
+	"simple_bank/token"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrRevokedToken = errors.New("token has been revoked")
+
+// List keeps the IDs of revoked tokens in memory so checking a token on every request
+// doesn't hit the database. Postgres stays the source of truth: other instances pick up
+// revocations the next time the list is refreshed by the sweeper.
+type List struct {
+	store db.Store
+
+	mu      sync.RWMutex
+	revoked map[uuid.UUID]time.Time
+}
+
+func NewList(store db.Store) *List {
+	return &List{
+		store:   store,
+		revoked: make(map[uuid.UUID]time.Time),
+	}
+}
+
+// Revoke persists the token ID and adds it to the in-memory set
+func (list *List) Revoke(ctx context.Context, payload *token.Payload) error {
+	err := list.store.CreateRevokedToken(ctx, db.CreateRevokedTokenParams{
+		ID:        payload.ID,
+		Username:  payload.Username,
+		ExpiresAt: payload.ExpiredAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	list.mu.Lock()
+	list.revoked[payload.ID] = payload.ExpiredAt
+	list.mu.Unlock()
+
+	return nil
+}
+
+func (list *List) IsRevoked(tokenID uuid.UUID) bool {
+	list.mu.RLock()
+	defer list.mu.RUnlock()
+
+	_, ok := list.revoked[tokenID]
+	return ok
+}
+
+// Refresh replaces the in-memory set with the unexpired revocations stored in the database
+func (list *List) Refresh(ctx context.Context) error {
+	tokens, err := list.store.ListActiveRevokedTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	revoked := make(map[uuid.UUID]time.Time, len(tokens))
+	for _, revokedToken := range tokens {
+		revoked[revokedToken.ID] = revokedToken.ExpiresAt
+	}
+
+	list.mu.Lock()
+	list.revoked = revoked
+	list.mu.Unlock()
+
+	return nil
+}
+
+// Sweep purges revocations whose tokens have expired anyway, then reloads the set
+func (list *List) Sweep(ctx context.Context) error {
+	_, err := list.store.DeleteExpiredRevokedTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	return list.Refresh(ctx)
+}
+
+// RunSweeper sweeps the list every interval until ctx is done
+func (list *List) RunSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := list.Sweep(ctx); err != nil {
+				log.Println("cannot sweep revoked tokens: ", err)
+			}
+		}
+	}
+}
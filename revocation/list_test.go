@@ -0,0 +1,116 @@
+package revocation
+
+import (
+	"context"
+	"database/sql"
+	mockdb "simple_bank/db/mock"
+	db "simple_bank/db/sqlc"
+	"simple_bank/token"
+	"simple_bank/util"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevoke(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	list := NewList(store)
+
+	payload, err := token.NewPayload(util.RandomOwner(), util.CustomerRole, token.TypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	arg := db.CreateRevokedTokenParams{
+		ID:        payload.ID,
+		Username:  payload.Username,
+		ExpiresAt: payload.ExpiredAt,
+	}
+	store.EXPECT().CreateRevokedToken(gomock.Any(), gomock.Eq(arg)).Times(1).Return(nil)
+
+	require.False(t, list.IsRevoked(payload.ID))
+	err = list.Revoke(context.Background(), payload)
+	require.NoError(t, err)
+	require.True(t, list.IsRevoked(payload.ID))
+}
+
+func TestRevokeError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	list := NewList(store)
+
+	payload, err := token.NewPayload(util.RandomOwner(), util.CustomerRole, token.TypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	store.EXPECT().CreateRevokedToken(gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+
+	err = list.Revoke(context.Background(), payload)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	require.False(t, list.IsRevoked(payload.ID))
+}
+
+func TestRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	list := NewList(store)
+
+	staleID := uuid.New()
+	list.revoked[staleID] = time.Now().Add(time.Minute)
+
+	revokedToken := db.RevokedToken{
+		ID:        uuid.New(),
+		Username:  util.RandomOwner(),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	store.EXPECT().
+		ListActiveRevokedTokens(gomock.Any()).
+		Times(1).
+		Return([]db.RevokedToken{revokedToken}, nil)
+
+	err := list.Refresh(context.Background())
+	require.NoError(t, err)
+	require.True(t, list.IsRevoked(revokedToken.ID))
+	require.False(t, list.IsRevoked(staleID))
+}
+
+func TestSweep(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	list := NewList(store)
+
+	expiredID := uuid.New()
+	list.revoked[expiredID] = time.Now().Add(-time.Minute)
+
+	gomock.InOrder(
+		store.EXPECT().DeleteExpiredRevokedTokens(gomock.Any()).Times(1).Return(int64(1), nil),
+		store.EXPECT().ListActiveRevokedTokens(gomock.Any()).Times(1).Return([]db.RevokedToken{}, nil),
+	)
+
+	err := list.Sweep(context.Background())
+	require.NoError(t, err)
+	require.False(t, list.IsRevoked(expiredID))
+}
+
+func TestSweepError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	list := NewList(store)
+
+	store.EXPECT().DeleteExpiredRevokedTokens(gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+	store.EXPECT().ListActiveRevokedTokens(gomock.Any()).Times(0)
+
+	err := list.Sweep(context.Background())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
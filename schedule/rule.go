@@ -0,0 +1,215 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequencies a rule can repeat at
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+)
+
+var ErrInvalidRule = errors.New("invalid schedule")
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Rule is the subset of an iCalendar RRULE scheduled transfers need,
+// e.g. "FREQ=MONTHLY;BYMONTHDAY=1" for the 1st of every month.
+// Occurrences keep the time of day of the first one.
+type Rule struct {
+	Freq     string
+	Interval int
+	// ByMonthDay is the day of the month for monthly rules. Days past the end of a month fall on its last day.
+	ByMonthDay int
+	// ByDay is the day of the week for weekly rules
+	ByDay *time.Weekday
+}
+
+// Parse reads a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR". An "RRULE:" prefix is allowed.
+func Parse(s string) (Rule, error) {
+	rule := Rule{Interval: 1}
+
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "RRULE:")
+	for _, part := range strings.Split(s, ";") {
+		if len(part) == 0 {
+			continue
+		}
+
+		pair := strings.SplitN(part, "=", 2)
+		if len(pair) != 2 {
+			return Rule{}, fmt.Errorf("%w: %q is not a KEY=VALUE pair", ErrInvalidRule, part)
+		}
+		key, value := pair[0], pair[1]
+
+		switch key {
+		case "FREQ":
+			if value != Daily && value != Weekly && value != Monthly {
+				return Rule{}, fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRule, value)
+			}
+			rule.Freq = value
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return Rule{}, fmt.Errorf("%w: INTERVAL must be a positive number", ErrInvalidRule)
+			}
+			rule.Interval = interval
+		case "BYMONTHDAY":
+			day, err := strconv.Atoi(value)
+			if err != nil || day < 1 || day > 31 {
+				return Rule{}, fmt.Errorf("%w: BYMONTHDAY must be between 1 and 31", ErrInvalidRule)
+			}
+			rule.ByMonthDay = day
+		case "BYDAY":
+			weekday, ok := weekdays[value]
+			if !ok {
+				return Rule{}, fmt.Errorf("%w: unsupported BYDAY %q", ErrInvalidRule, value)
+			}
+			rule.ByDay = &weekday
+		default:
+			return Rule{}, fmt.Errorf("%w: unsupported part %q", ErrInvalidRule, key)
+		}
+	}
+
+	switch {
+	case len(rule.Freq) == 0:
+		return Rule{}, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	case rule.ByMonthDay != 0 && rule.Freq != Monthly:
+		return Rule{}, fmt.Errorf("%w: BYMONTHDAY only applies to monthly rules", ErrInvalidRule)
+	case rule.ByDay != nil && rule.Freq != Weekly:
+		return Rule{}, fmt.Errorf("%w: BYDAY only applies to weekly rules", ErrInvalidRule)
+	}
+
+	return rule, nil
+}
+
+func (rule Rule) String() string {
+	parts := []string{"FREQ=" + rule.Freq}
+	if rule.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(rule.Interval))
+	}
+	if rule.ByMonthDay != 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(rule.ByMonthDay))
+	}
+	if rule.ByDay != nil {
+		for code, weekday := range weekdays {
+			if weekday == *rule.ByDay {
+				parts = append(parts, "BYDAY="+code)
+			}
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// Anchor fills in the day a rule repeats on from its first occurrence when the rule leaves it out,
+// so later occurrences don't drift after a short month.
+func (rule Rule) Anchor(start time.Time) Rule {
+	switch rule.Freq {
+	case Monthly:
+		if rule.ByMonthDay == 0 {
+			rule.ByMonthDay = start.Day()
+		}
+	case Weekly:
+		if rule.ByDay == nil {
+			weekday := start.Weekday()
+			rule.ByDay = &weekday
+		}
+	}
+	return rule
+}
+
+// First returns the first occurrence at or after start
+func (rule Rule) First(start time.Time) time.Time {
+	switch rule.Freq {
+	case Monthly:
+		if rule.ByMonthDay == 0 {
+			return start
+		}
+		first := monthDay(start, 0, rule.ByMonthDay)
+		if first.Before(start) {
+			first = monthDay(start, 1, rule.ByMonthDay)
+		}
+		return first
+	case Weekly:
+		if rule.ByDay == nil {
+			return start
+		}
+		days := (int(*rule.ByDay) - int(start.Weekday()) + 7) % 7
+		return start.AddDate(0, 0, days)
+	}
+	return start
+}
+
+// Next returns the occurrence following prev
+func (rule Rule) Next(prev time.Time) time.Time {
+	switch rule.Freq {
+	case Monthly:
+		day := rule.ByMonthDay
+		if day == 0 {
+			day = prev.Day()
+		}
+		return monthDay(prev, rule.Interval, day)
+	case Weekly:
+		return prev.AddDate(0, 0, 7*rule.Interval)
+	}
+	return prev.AddDate(0, 0, rule.Interval)
+}
+
+// monthDay returns the given day of the month months after t, clamped to the end of that month
+func monthDay(t time.Time, months int, day int) time.Time {
+	year, month, _ := t.Date()
+	firstOfMonth := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+
+	if last := firstOfMonth.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+// NextRun returns when a scheduled transfer that last ran at prev should run next, skipping any
+// occurrences missed up to now. It returns nil for a one-off transfer, which has no schedule.
+func NextRun(schedule string, prev time.Time, now time.Time) (*time.Time, error) {
+	if len(schedule) == 0 {
+		return nil, nil
+	}
+
+	rule, err := Parse(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	next := rule.Next(prev)
+	for !next.After(now) {
+		next = rule.Next(next)
+	}
+	return &next, nil
+}
+
+// Plan normalises a schedule and works out its first run at or after start.
+// An empty schedule is a one-off transfer that runs at start.
+func Plan(schedule string, start time.Time) (string, time.Time, error) {
+	if len(strings.TrimSpace(schedule)) == 0 {
+		return "", start, nil
+	}
+
+	rule, err := Parse(schedule)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	rule = rule.Anchor(start)
+	return rule.String(), rule.First(start), nil
+}
@@ -0,0 +1,134 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		rule    string
+		want    string
+		wantErr bool
+	}{
+		{name: "Monthly", rule: "FREQ=MONTHLY;BYMONTHDAY=1", want: "FREQ=MONTHLY;BYMONTHDAY=1"},
+		{name: "RRulePrefix", rule: "RRULE:freq=weekly;byday=fr;interval=2", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR"},
+		{name: "Daily", rule: "FREQ=DAILY;INTERVAL=1", want: "FREQ=DAILY"},
+		{name: "MissingFreq", rule: "BYMONTHDAY=1", wantErr: true},
+		{name: "UnsupportedFreq", rule: "FREQ=YEARLY", wantErr: true},
+		{name: "InvalidInterval", rule: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{name: "InvalidMonthDay", rule: "FREQ=MONTHLY;BYMONTHDAY=32", wantErr: true},
+		{name: "MonthDayOnWeeklyRule", rule: "FREQ=WEEKLY;BYMONTHDAY=1", wantErr: true},
+		{name: "DayOnMonthlyRule", rule: "FREQ=MONTHLY;BYDAY=MO", wantErr: true},
+		{name: "UnsupportedPart", rule: "FREQ=DAILY;COUNT=3", wantErr: true},
+		{name: "NotAPair", rule: "FREQ", wantErr: true},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := Parse(tc.rule)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidRule)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, rule.String())
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	testCases := []struct {
+		name      string
+		schedule  string
+		start     time.Time
+		wantRule  string
+		wantFirst time.Time
+	}{
+		{
+			name:      "OneOff",
+			start:     date(2022, time.March, 15),
+			wantFirst: date(2022, time.March, 15),
+		},
+		{
+			name:      "FirstOfNextMonth",
+			schedule:  "FREQ=MONTHLY;BYMONTHDAY=1",
+			start:     date(2022, time.March, 15),
+			wantRule:  "FREQ=MONTHLY;BYMONTHDAY=1",
+			wantFirst: date(2022, time.April, 1),
+		},
+		{
+			name:      "MonthlyAnchoredOnStart",
+			schedule:  "FREQ=MONTHLY",
+			start:     date(2022, time.January, 31),
+			wantRule:  "FREQ=MONTHLY;BYMONTHDAY=31",
+			wantFirst: date(2022, time.January, 31),
+		},
+		{
+			name:      "NextFriday",
+			schedule:  "FREQ=WEEKLY;BYDAY=FR",
+			start:     date(2022, time.March, 15),
+			wantRule:  "FREQ=WEEKLY;BYDAY=FR",
+			wantFirst: date(2022, time.March, 18),
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			rule, first, err := Plan(tc.schedule, tc.start)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantRule, rule)
+			require.Equal(t, tc.wantFirst, first)
+		})
+	}
+
+	_, _, err := Plan("FREQ=HOURLY", date(2022, time.March, 15))
+	require.ErrorIs(t, err, ErrInvalidRule)
+}
+
+func TestNext(t *testing.T) {
+	rule, err := Parse("FREQ=MONTHLY;BYMONTHDAY=31")
+	require.NoError(t, err)
+
+	// short months fall on their last day without moving later months
+	next := rule.Next(date(2022, time.January, 31))
+	require.Equal(t, date(2022, time.February, 28), next)
+	require.Equal(t, date(2022, time.March, 31), rule.Next(next))
+
+	rule, err = Parse("FREQ=WEEKLY;INTERVAL=2;BYDAY=FR")
+	require.NoError(t, err)
+	require.Equal(t, date(2022, time.April, 1), rule.Next(date(2022, time.March, 18)))
+
+	rule, err = Parse("FREQ=DAILY;INTERVAL=3")
+	require.NoError(t, err)
+	require.Equal(t, date(2022, time.March, 18), rule.Next(date(2022, time.March, 15)))
+}
+
+func TestNextRun(t *testing.T) {
+	next, err := NextRun("", date(2022, time.March, 1), date(2022, time.March, 1))
+	require.NoError(t, err)
+	require.Nil(t, next)
+
+	next, err = NextRun("FREQ=MONTHLY;BYMONTHDAY=1", date(2022, time.March, 1), date(2022, time.March, 1))
+	require.NoError(t, err)
+	require.Equal(t, date(2022, time.April, 1), *next)
+
+	// occurrences missed while nothing was running are skipped rather than paid late
+	next, err = NextRun("FREQ=MONTHLY;BYMONTHDAY=1", date(2022, time.March, 1), date(2022, time.June, 15))
+	require.NoError(t, err)
+	require.Equal(t, date(2022, time.July, 1), *next)
+
+	_, err = NextRun("FREQ=HOURLY", date(2022, time.March, 1), date(2022, time.March, 1))
+	require.ErrorIs(t, err, ErrInvalidRule)
+}
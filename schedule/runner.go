@@ -0,0 +1,104 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"log"
+	db "simple_bank/db/sqlc"
+	"time"
+)
+
+const runBatchSize = 100
+
+// Runner executes scheduled transfers when they fall due. Each run is claimed before its transfer is
+// attempted, so a transfer is never made twice for the same occurrence, even with several runners or a
+// crash part way; a run left pending was claimed but may not have been attempted.
+type Runner struct {
+	store db.Store
+}
+
+func NewRunner(store db.Store) *Runner {
+	return &Runner{store: store}
+}
+
+// RunDue executes a batch of the scheduled transfers due at now and returns how many runs it finished
+func (runner *Runner) RunDue(ctx context.Context, now time.Time) (int, error) {
+	due, err := runner.store.ListDueScheduledTransfers(ctx, db.ListDueScheduledTransfersParams{
+		Now:      now,
+		PageSize: runBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	finished := 0
+	for _, scheduled := range due {
+		ran, err := runner.run(ctx, scheduled, now)
+		if err != nil {
+			log.Printf("cannot run scheduled transfer [%d]: %v", scheduled.ID, err)
+			continue
+		}
+		if ran {
+			finished++
+		}
+	}
+
+	return finished, nil
+}
+
+func (runner *Runner) run(ctx context.Context, scheduled db.ScheduledTransfer, now time.Time) (bool, error) {
+	nextRunAt, err := NextRun(scheduled.Schedule, *scheduled.NextRunAt, now)
+	if err != nil {
+		return false, err
+	}
+
+	run, err := runner.store.ClaimScheduledTransferTx(ctx, db.ClaimScheduledTransferTxParams{
+		ID:           scheduled.ID,
+		ScheduledFor: *scheduled.NextRunAt,
+		NextRunAt:    nextRunAt,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrScheduledRunClaimed) {
+			// another runner got to it first
+			return false, nil
+		}
+		return false, err
+	}
+
+	arg := db.FinishScheduledTransferRunParams{
+		ID:     run.ID,
+		Status: db.ScheduledRunStatusSucceeded,
+	}
+
+	result, err := runner.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID: scheduled.FromAccountID,
+		ToAccountID:   scheduled.ToAccountID,
+		Amount:        scheduled.Amount,
+	})
+	if err != nil {
+		arg.Status = db.ScheduledRunStatusFailed
+		arg.FailureReason = err.Error()
+	} else {
+		arg.TransferID = &result.Transfer.ID
+	}
+
+	_, err = runner.store.FinishScheduledTransferRun(ctx, arg)
+	return err == nil, err
+}
+
+// Run executes due scheduled transfers every interval until ctx is done
+func (runner *Runner) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := runner.RunDue(ctx, time.Now()); err != nil {
+				log.Println("cannot run scheduled transfers: ", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package schedule
+
+import (
+	"context"
+	mockdb "simple_bank/db/mock"
+	db "simple_bank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func randomScheduledTransfer(id int64, schedule string, nextRunAt time.Time) db.ScheduledTransfer {
+	return db.ScheduledTransfer{
+		ID:            id,
+		Owner:         "owner",
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        100,
+		Currency:      "USD",
+		Schedule:      schedule,
+		Status:        db.ScheduledTransferStatusActive,
+		NextRunAt:     &nextRunAt,
+	}
+}
+
+func TestRunDue(t *testing.T) {
+	now := date(2022, time.March, 1)
+	monthly := randomScheduledTransfer(1, "FREQ=MONTHLY;BYMONTHDAY=1", now)
+	oneOff := randomScheduledTransfer(2, "", now)
+	claimed := randomScheduledTransfer(3, "", now)
+	nextMonth := date(2022, time.April, 1)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	runner := NewRunner(store)
+
+	store.EXPECT().
+		ListDueScheduledTransfers(gomock.Any(), gomock.Eq(db.ListDueScheduledTransfersParams{Now: now, PageSize: runBatchSize})).
+		Times(1).
+		Return([]db.ScheduledTransfer{monthly, oneOff, claimed}, nil)
+
+	// the monthly transfer succeeds and moves on to next month
+	store.EXPECT().
+		ClaimScheduledTransferTx(gomock.Any(), gomock.Eq(db.ClaimScheduledTransferTxParams{ID: monthly.ID, ScheduledFor: now, NextRunAt: &nextMonth})).
+		Times(1).
+		Return(db.ScheduledTransferRun{ID: 10}, nil)
+	store.EXPECT().
+		TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{FromAccountID: 1, ToAccountID: 2, Amount: 100})).
+		Times(1).
+		Return(db.TransferTxResult{Transfer: db.Transfer{ID: 7}}, nil)
+	transferID := int64(7)
+	store.EXPECT().
+		FinishScheduledTransferRun(gomock.Any(), gomock.Eq(db.FinishScheduledTransferRunParams{ID: 10, Status: db.ScheduledRunStatusSucceeded, TransferID: &transferID})).
+		Times(1)
+
+	// the one-off transfer fails and records why
+	store.EXPECT().
+		ClaimScheduledTransferTx(gomock.Any(), gomock.Eq(db.ClaimScheduledTransferTxParams{ID: oneOff.ID, ScheduledFor: now})).
+		Times(1).
+		Return(db.ScheduledTransferRun{ID: 11}, nil)
+	store.EXPECT().
+		TransferTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+	store.EXPECT().
+		FinishScheduledTransferRun(gomock.Any(), gomock.Eq(db.FinishScheduledTransferRunParams{ID: 11, Status: db.ScheduledRunStatusFailed, FailureReason: db.ErrInsufficientFunds.Error()})).
+		Times(1)
+
+	// another runner claimed the last one first, so it is not transferred again
+	store.EXPECT().
+		ClaimScheduledTransferTx(gomock.Any(), gomock.Eq(db.ClaimScheduledTransferTxParams{ID: claimed.ID, ScheduledFor: now})).
+		Times(1).
+		Return(db.ScheduledTransferRun{}, db.ErrScheduledRunClaimed)
+
+	finished, err := runner.RunDue(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, 2, finished)
+}
+
+func TestRunDueNothingDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	runner := NewRunner(store)
+
+	store.EXPECT().ListDueScheduledTransfers(gomock.Any(), gomock.Any()).Times(1).Return([]db.ScheduledTransfer{}, nil)
+	store.EXPECT().ClaimScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+	finished, err := runner.RunDue(context.Background(), time.Now())
+	require.NoError(t, err)
+	require.Zero(t, finished)
+}
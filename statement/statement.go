@@ -0,0 +1,105 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	db "simple_bank/db/sqlc"
+	"time"
+)
+
+var ErrInvalidPeriod = errors.New("statement period must end after it starts")
+
+type Statement struct {
+	AccountID      int64     `json:"account_id"`
+	Owner          string    `json:"owner"`
+	Currency       string    `json:"currency"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	OpeningBalance int64     `json:"opening_balance"`
+	Lines          []Line    `json:"lines"`
+	ClosingBalance int64     `json:"closing_balance"`
+	// Reconciled is false when accounts.balance disagrees with the sum of the account's entries,
+	// e.g. after a balance was overwritten without booking an entry
+	Reconciled  bool      `json:"reconciled"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+type Line struct {
+	EntryID               int64     `json:"entry_id"`
+	TransferID            *int64    `json:"transfer_id,omitempty"`
+	CounterpartyAccountID *int64    `json:"counterparty_account_id,omitempty"`
+	Amount                int64     `json:"amount"`
+	Balance               int64     `json:"balance"`
+	CreatedAt             time.Time `json:"created_at"`
+}
+
+// Service builds account statements from the ledger entries
+type Service struct {
+	store db.Store
+}
+
+func NewService(store db.Store) *Service {
+	return &Service{store: store}
+}
+
+// Generate returns the statement of the account for entries created in [from, to)
+func (service *Service) Generate(ctx context.Context, accountID int64, from time.Time, to time.Time) (Statement, error) {
+	if !to.After(from) {
+		return Statement{}, ErrInvalidPeriod
+	}
+
+	result, err := service.store.StatementTx(ctx, db.StatementTxParams{
+		AccountID: accountID,
+		From:      from,
+		To:        to,
+	})
+	if err != nil {
+		return Statement{}, err
+	}
+
+	return build(result, from, to), nil
+}
+
+func build(result db.StatementTxResult, from time.Time, to time.Time) Statement {
+	statement := Statement{
+		AccountID:      result.Account.ID,
+		Owner:          result.Account.Owner,
+		Currency:       result.Account.Currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: result.OpeningBalance,
+		Lines:          make([]Line, 0, len(result.Entries)),
+		Reconciled:     result.LedgerBalance == result.Account.Balance,
+		GeneratedAt:    time.Now().UTC(),
+	}
+
+	balance := result.OpeningBalance
+	for _, entry := range result.Entries {
+		balance += entry.Amount
+
+		statement.Lines = append(statement.Lines, Line{
+			EntryID:               entry.ID,
+			TransferID:            entry.TransferID,
+			CounterpartyAccountID: counterparty(entry),
+			Amount:                entry.Amount,
+			Balance:               balance,
+			CreatedAt:             entry.CreatedAt,
+		})
+	}
+	statement.ClosingBalance = balance
+
+	return statement
+}
+
+// counterparty returns the other account of the transfer the entry belongs to, if any
+func counterparty(entry db.ListStatementEntriesRow) *int64 {
+	if !entry.FromAccountID.Valid || !entry.ToAccountID.Valid {
+		return nil
+	}
+
+	accountID := entry.FromAccountID.Int64
+	if accountID == entry.AccountID {
+		accountID = entry.ToAccountID.Int64
+	}
+	return &accountID
+}
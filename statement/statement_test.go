@@ -0,0 +1,115 @@
+package statement
+
+import (
+	"context"
+	"database/sql"
+	mockdb "simple_bank/db/mock"
+	db "simple_bank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	service := NewService(store)
+
+	from := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	transferID := int64(7)
+
+	result := db.StatementTxResult{
+		Account: db.Account{
+			ID:       1,
+			Owner:    "owner",
+			Balance:  130,
+			Currency: "USD",
+		},
+		OpeningBalance: 100,
+		Entries: []db.ListStatementEntriesRow{
+			{
+				ID:        10,
+				AccountID: 1,
+				Amount:    50,
+				CreatedAt: from.Add(time.Hour),
+			},
+			{
+				ID:            11,
+				AccountID:     1,
+				Amount:        -20,
+				TransferID:    &transferID,
+				CreatedAt:     from.Add(2 * time.Hour),
+				FromAccountID: sql.NullInt64{Int64: 1, Valid: true},
+				ToAccountID:   sql.NullInt64{Int64: 2, Valid: true},
+			},
+		},
+		LedgerBalance: 130,
+	}
+
+	arg := db.StatementTxParams{AccountID: 1, From: from, To: to}
+	store.EXPECT().StatementTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
+
+	statement, err := service.Generate(context.Background(), 1, from, to)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(1), statement.AccountID)
+	require.Equal(t, "USD", statement.Currency)
+	require.Equal(t, int64(100), statement.OpeningBalance)
+	require.Equal(t, int64(130), statement.ClosingBalance)
+	require.True(t, statement.Reconciled)
+
+	require.Len(t, statement.Lines, 2)
+	require.Equal(t, int64(150), statement.Lines[0].Balance)
+	require.Nil(t, statement.Lines[0].TransferID)
+	require.Nil(t, statement.Lines[0].CounterpartyAccountID)
+
+	require.Equal(t, int64(130), statement.Lines[1].Balance)
+	require.Equal(t, &transferID, statement.Lines[1].TransferID)
+	require.NotNil(t, statement.Lines[1].CounterpartyAccountID)
+	require.Equal(t, int64(2), *statement.Lines[1].CounterpartyAccountID)
+}
+
+func TestGenerateUnreconciled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	service := NewService(store)
+
+	result := db.StatementTxResult{
+		Account:        db.Account{ID: 1, Balance: 500},
+		OpeningBalance: 0,
+		Entries:        []db.ListStatementEntriesRow{},
+		LedgerBalance:  100,
+	}
+	store.EXPECT().StatementTx(gomock.Any(), gomock.Any()).Times(1).Return(result, nil)
+
+	from := time.Now().Add(-time.Hour)
+	statement, err := service.Generate(context.Background(), 1, from, time.Now())
+	require.NoError(t, err)
+	require.False(t, statement.Reconciled)
+	require.Empty(t, statement.Lines)
+	require.Equal(t, statement.OpeningBalance, statement.ClosingBalance)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	service := NewService(store)
+
+	now := time.Now()
+	_, err := service.Generate(context.Background(), 1, now, now)
+	require.ErrorIs(t, err, ErrInvalidPeriod)
+
+	store.EXPECT().StatementTx(gomock.Any(), gomock.Any()).Times(1).Return(db.StatementTxResult{}, sql.ErrConnDone)
+
+	_, err = service.Generate(context.Background(), 1, now.Add(-time.Hour), now)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
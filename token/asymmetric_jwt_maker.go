@@ -0,0 +1,319 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const minRSAKeyBits = 2048
+
+// AsymmetricJWTMaker signs tokens with a private key (RS256 or EdDSA) and verifies them
+// against a set of public keys identified by the "kid" header, so verifiers never need
+// the signing key and old keys keep validating outstanding tokens after a rotation.
+type AsymmetricJWTMaker struct {
+	mu               sync.RWMutex
+	signingKey       *signingKey
+	verificationKeys map[string]*verificationKey
+}
+
+type signingKey struct {
+	id         string
+	method     jwt.SigningMethod
+	privateKey crypto.PrivateKey
+}
+
+type verificationKey struct {
+	id        string
+	method    jwt.SigningMethod
+	publicKey crypto.PublicKey
+}
+
+// NewAsymmetricJWTMaker creates a maker from a PEM encoded private key. Additional PEM encoded
+// public keys are accepted for verification only, e.g. keys that signed before a rotation.
+func NewAsymmetricJWTMaker(signingKeyPEM []byte, verificationKeyPEMs ...[]byte) (*AsymmetricJWTMaker, error) {
+	maker := &AsymmetricJWTMaker{
+		verificationKeys: make(map[string]*verificationKey),
+	}
+
+	for _, keyPEM := range verificationKeyPEMs {
+		publicKey, err := parsePublicKeyPEM(keyPEM)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := newVerificationKey(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		maker.verificationKeys[key.id] = key
+	}
+
+	if err := maker.RotateSigningKey(signingKeyPEM); err != nil {
+		return nil, err
+	}
+
+	return maker, nil
+}
+
+// NewAsymmetricJWTMakerFromFiles loads the signing key and verification keys from PEM files
+func NewAsymmetricJWTMakerFromFiles(signingKeyFile string, verificationKeyFiles []string) (*AsymmetricJWTMaker, error) {
+	signingKeyPEM, err := os.ReadFile(signingKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read signing key: %w", err)
+	}
+
+	verificationKeyPEMs := make([][]byte, 0, len(verificationKeyFiles))
+	for _, file := range verificationKeyFiles {
+		if len(file) == 0 {
+			continue
+		}
+
+		keyPEM, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read verification key: %w", err)
+		}
+		verificationKeyPEMs = append(verificationKeyPEMs, keyPEM)
+	}
+
+	return NewAsymmetricJWTMaker(signingKeyPEM, verificationKeyPEMs...)
+}
+
+// RotateSigningKey makes the given PEM encoded private key the one used for new tokens.
+// The public half of the previous signing key remains a verification key.
+func (maker *AsymmetricJWTMaker) RotateSigningKey(signingKeyPEM []byte) error {
+	privateKey, err := parsePrivateKeyPEM(signingKeyPEM)
+	if err != nil {
+		return err
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return errors.New("private key cannot be used for signing")
+	}
+
+	key, err := newVerificationKey(signer.Public())
+	if err != nil {
+		return err
+	}
+
+	maker.mu.Lock()
+	defer maker.mu.Unlock()
+
+	maker.verificationKeys[key.id] = key
+	maker.signingKey = &signingKey{
+		id:         key.id,
+		method:     key.method,
+		privateKey: privateKey,
+	}
+
+	return nil
+}
+
+// SigningKeyID returns the kid stamped on newly created tokens
+func (maker *AsymmetricJWTMaker) SigningKeyID() string {
+	maker.mu.RLock()
+	defer maker.mu.RUnlock()
+
+	return maker.signingKey.id
+}
+
+func (maker *AsymmetricJWTMaker) CreateToken(username string, role string, tokenType Type, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, tokenType, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	maker.mu.RLock()
+	key := maker.signingKey
+	maker.mu.RUnlock()
+
+	jwtToken := jwt.NewWithClaims(key.method, payload)
+	jwtToken.Header["kid"] = key.id
+
+	token, err := jwtToken.SignedString(key.privateKey)
+	return token, payload, err
+}
+
+func (maker *AsymmetricJWTMaker) VerifyToken(token string) (*Payload, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		maker.mu.RLock()
+		key, ok := maker.verificationKeys[kid]
+		maker.mu.RUnlock()
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, ErrInvalidToken
+		}
+		return key.publicKey, nil
+	}
+
+	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
+	if err != nil {
+		verr, ok := err.(*jwt.ValidationError)
+		if ok && errors.Is(verr.Inner, ErrExpiredToken) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	payload, ok := jwtToken.Claims.(*Payload)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return payload, nil
+}
+
+// JWKS returns every verification key as a JSON Web Key Set
+func (maker *AsymmetricJWTMaker) JWKS() JWKSet {
+	maker.mu.RLock()
+	defer maker.mu.RUnlock()
+
+	set := JWKSet{Keys: make([]JWK, 0, len(maker.verificationKeys))}
+	for _, key := range maker.verificationKeys {
+		set.Keys = append(set.Keys, newJWK(key))
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+	return set
+}
+
+// KeySetProvider is implemented by makers whose verification keys can be published
+type KeySetProvider interface {
+	JWKS() JWKSet
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+func newJWK(key *verificationKey) JWK {
+	jwk := JWK{
+		Use: "sig",
+		Alg: key.method.Alg(),
+		Kid: key.id,
+	}
+
+	switch publicKey := key.publicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	}
+
+	return jwk
+}
+
+func newVerificationKey(publicKey crypto.PublicKey) (*verificationKey, error) {
+	key := &verificationKey{publicKey: publicKey}
+
+	switch publicKey := publicKey.(type) {
+	case *rsa.PublicKey:
+		if publicKey.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("invalid key size: RSA keys must be at least %d bits", minRSAKeyBits)
+		}
+		key.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported public key type: %T", publicKey)
+	}
+
+	key.id = thumbprint(newJWK(key))
+	return key, nil
+}
+
+// thumbprint computes the RFC 7638 JWK thumbprint used as the key id
+func thumbprint(jwk JWK) string {
+	var members interface{}
+
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func parsePrivateKeyPEM(data []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid private key: no PEM block found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported private key PEM type: %s", block.Type)
+	}
+}
+
+func parsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid public key: no PEM block found")
+	}
+
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported public key PEM type: %s", block.Type)
+	}
+}
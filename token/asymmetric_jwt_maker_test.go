@@ -0,0 +1,268 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"simple_bank/util"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func newRSAPrivateKeyPEM(t *testing.T) []byte {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+}
+
+func newEd25519PrivateKeyPEM(t *testing.T) []byte {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func publicKeyPEM(t *testing.T, privateKeyPEM []byte) []byte {
+	privateKey, err := parsePrivateKeyPEM(privateKeyPEM)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(privateKey.(crypto.Signer).Public())
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func writeKeyFile(t *testing.T, data []byte) string {
+	file := filepath.Join(t.TempDir(), util.RandomString(8)+".pem")
+	err := os.WriteFile(file, data, 0600)
+	require.NoError(t, err)
+	return file
+}
+
+func TestAsymmetricJWTMaker(t *testing.T) {
+	testCases := []struct {
+		name      string
+		keyPEM    []byte
+		algorithm string
+	}{
+		{
+			name:      "RS256",
+			keyPEM:    newRSAPrivateKeyPEM(t),
+			algorithm: jwt.SigningMethodRS256.Alg(),
+		},
+		{
+			name:      "EdDSA",
+			keyPEM:    newEd25519PrivateKeyPEM(t),
+			algorithm: jwt.SigningMethodEdDSA.Alg(),
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			maker, err := NewAsymmetricJWTMaker(tc.keyPEM)
+			require.NoError(t, err)
+
+			username := util.RandomOwner()
+			duration := time.Minute
+
+			issuedAt := time.Now()
+			expiredAt := issuedAt.Add(duration)
+
+			token, payload, err := maker.CreateToken(username, util.CustomerRole, TypeAccess, duration)
+			require.NoError(t, err)
+			require.NotEmpty(t, token)
+			require.NotEmpty(t, payload)
+
+			jwtToken, _, err := new(jwt.Parser).ParseUnverified(token, &Payload{})
+			require.NoError(t, err)
+			require.Equal(t, tc.algorithm, jwtToken.Method.Alg())
+			require.Equal(t, maker.SigningKeyID(), jwtToken.Header["kid"])
+
+			payload, err = maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.NotEmpty(t, payload)
+
+			require.NotZero(t, payload.ID)
+			require.Equal(t, username, payload.Username)
+			require.Equal(t, util.CustomerRole, payload.Role)
+			require.Equal(t, TypeAccess, payload.Type)
+			require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+			require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+		})
+	}
+}
+
+func TestExpiredAsymmetricJWTToken(t *testing.T) {
+	maker, err := NewAsymmetricJWTMaker(newEd25519PrivateKeyPEM(t))
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken(util.RandomOwner(), util.CustomerRole, TypeAccess, -time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestInvalidAsymmetricJWTToken(t *testing.T) {
+	maker, err := NewAsymmetricJWTMaker(newEd25519PrivateKeyPEM(t))
+	require.NoError(t, err)
+
+	payload, err := NewPayload(util.RandomOwner(), util.CustomerRole, TypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, payload)
+	jwtToken.Header["kid"] = maker.SigningKeyID()
+	token, err := jwtToken.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	payload, err = maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestTamperedAsymmetricJWTToken(t *testing.T) {
+	maker, err := NewAsymmetricJWTMaker(newRSAPrivateKeyPEM(t))
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), util.CustomerRole, TypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	// swap the claims for another user's while keeping the original signature
+	parts := strings.Split(token, ".")
+	otherToken, _, err := maker.CreateToken(util.RandomOwner(), util.CustomerRole, TypeAccess, time.Minute)
+	require.NoError(t, err)
+	parts[1] = strings.Split(otherToken, ".")[1]
+
+	payload, err := maker.VerifyToken(strings.Join(parts, "."))
+	require.Error(t, err)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestUnknownKeyAsymmetricJWTToken(t *testing.T) {
+	maker1, err := NewAsymmetricJWTMaker(newEd25519PrivateKeyPEM(t))
+	require.NoError(t, err)
+
+	maker2, err := NewAsymmetricJWTMaker(newEd25519PrivateKeyPEM(t))
+	require.NoError(t, err)
+
+	token, _, err := maker1.CreateToken(util.RandomOwner(), util.CustomerRole, TypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker2.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestRotateAsymmetricJWTSigningKey(t *testing.T) {
+	maker, err := NewAsymmetricJWTMaker(newRSAPrivateKeyPEM(t))
+	require.NoError(t, err)
+
+	oldKeyID := maker.SigningKeyID()
+	oldToken, _, err := maker.CreateToken(util.RandomOwner(), util.CustomerRole, TypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	err = maker.RotateSigningKey(newEd25519PrivateKeyPEM(t))
+	require.NoError(t, err)
+	require.NotEqual(t, oldKeyID, maker.SigningKeyID())
+
+	newToken, _, err := maker.CreateToken(util.RandomOwner(), util.CustomerRole, TypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	jwtToken, _, err := new(jwt.Parser).ParseUnverified(newToken, &Payload{})
+	require.NoError(t, err)
+	require.Equal(t, maker.SigningKeyID(), jwtToken.Header["kid"])
+
+	// tokens signed before the rotation stay valid
+	_, err = maker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(newToken)
+	require.NoError(t, err)
+
+	keySet := maker.JWKS()
+	require.Len(t, keySet.Keys, 2)
+}
+
+func TestAsymmetricJWTMakerFromFiles(t *testing.T) {
+	oldKeyPEM := newRSAPrivateKeyPEM(t)
+	oldMaker, err := NewAsymmetricJWTMaker(oldKeyPEM)
+	require.NoError(t, err)
+
+	oldToken, _, err := oldMaker.CreateToken(util.RandomOwner(), util.CustomerRole, TypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	signingKeyFile := writeKeyFile(t, newEd25519PrivateKeyPEM(t))
+	verificationKeyFile := writeKeyFile(t, publicKeyPEM(t, oldKeyPEM))
+
+	maker, err := NewAsymmetricJWTMakerFromFiles(signingKeyFile, []string{verificationKeyFile})
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	keySet := maker.JWKS()
+	require.Len(t, keySet.Keys, 2)
+	for _, key := range keySet.Keys {
+		require.Equal(t, "sig", key.Use)
+		require.NotEmpty(t, key.Kid)
+
+		switch key.Kty {
+		case "RSA":
+			require.Equal(t, oldMaker.SigningKeyID(), key.Kid)
+			require.Equal(t, "RS256", key.Alg)
+			require.NotEmpty(t, key.N)
+			require.NotEmpty(t, key.E)
+		case "OKP":
+			require.Equal(t, maker.SigningKeyID(), key.Kid)
+			require.Equal(t, "EdDSA", key.Alg)
+			require.Equal(t, "Ed25519", key.Crv)
+			require.NotEmpty(t, key.X)
+		default:
+			t.Fatalf("unexpected key type %s", key.Kty)
+		}
+	}
+
+	_, err = NewAsymmetricJWTMakerFromFiles("missing.pem", nil)
+	require.Error(t, err)
+}
+
+func TestInvalidAsymmetricJWTKey(t *testing.T) {
+	_, err := NewAsymmetricJWTMaker([]byte("not a pem"))
+	require.Error(t, err)
+
+	smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	smallKeyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(smallKey),
+	})
+
+	_, err = NewAsymmetricJWTMaker(smallKeyPEM)
+	require.Error(t, err)
+}
@@ -1,11 +1,37 @@
 package token
 
 import (
+	"fmt"
+	"simple_bank/util"
 	"time"
 )
 
+const (
+	TokenTypeJWT           = "jwt"
+	TokenTypePaseto        = "paseto"
+	TokenTypeAsymmetricJWT = "asymmetric_jwt"
+)
+
 type Maker interface {
-	CreateToken(username string, duration time.Duration) (string, error)
+	CreateToken(username string, role string, tokenType Type, duration time.Duration) (string, *Payload, error)
 
 	VerifyToken(token string) (*Payload, error)
 }
+
+// NewMaker creates the token maker selected by config.TokenType, defaulting to JWT
+func NewMaker(config util.Config) (Maker, error) {
+	switch config.TokenType {
+	case "", TokenTypeJWT:
+		return NewJWTMaker(config.TokenSymmetricKey)
+	case TokenTypePaseto:
+		return NewPasetoMaker(config.TokenSymmetricKey)
+	case TokenTypeAsymmetricJWT:
+		maker, err := NewAsymmetricJWTMakerFromFiles(config.TokenSigningKeyFile, config.TokenVerificationKeyFiles)
+		if err != nil {
+			return nil, err
+		}
+		return maker, nil
+	default:
+		return nil, fmt.Errorf("unsupported token type: %s", config.TokenType)
+	}
+}
@@ -0,0 +1,44 @@
+package token
+
+import (
+	"simple_bank/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMaker(t *testing.T) {
+	config := util.Config{
+		TokenSymmetricKey: util.RandomString(32),
+	}
+
+	maker, err := NewMaker(config)
+	require.NoError(t, err)
+	require.IsType(t, &JWTMaker{}, maker)
+
+	config.TokenType = TokenTypeJWT
+	maker, err = NewMaker(config)
+	require.NoError(t, err)
+	require.IsType(t, &JWTMaker{}, maker)
+
+	config.TokenType = TokenTypePaseto
+	maker, err = NewMaker(config)
+	require.NoError(t, err)
+	require.IsType(t, &PasetoMaker{}, maker)
+
+	config.TokenType = TokenTypeAsymmetricJWT
+	config.TokenSigningKeyFile = writeKeyFile(t, newEd25519PrivateKeyPEM(t))
+	maker, err = NewMaker(config)
+	require.NoError(t, err)
+	require.IsType(t, &AsymmetricJWTMaker{}, maker)
+
+	config.TokenSigningKeyFile = "missing.pem"
+	maker, err = NewMaker(config)
+	require.Error(t, err)
+	require.Nil(t, maker)
+
+	config.TokenType = "unsupported"
+	maker, err = NewMaker(config)
+	require.Error(t, err)
+	require.Nil(t, maker)
+}
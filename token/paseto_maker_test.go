@@ -0,0 +1,96 @@
+package token
+
+import (
+	"simple_bank/util"
+	"testing"
+	"time"
+
+	"github.com/o1egl/paseto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasetoMaker(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+	duration := time.Minute
+
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	token, payload, err := maker.CreateToken(username, util.CustomerRole, TypeAccess, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	require.NotZero(t, payload.ID)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, util.CustomerRole, payload.Role)
+	require.Equal(t, TypeAccess, payload.Type)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+}
+
+func TestExpiredPasetoToken(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken(util.RandomOwner(), util.CustomerRole, TypeAccess, -time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestInvalidPasetoToken(t *testing.T) {
+	payload, err := NewPayload(util.RandomOwner(), util.CustomerRole, TypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	// encrypted with a different key
+	token, err := paseto.NewV2().Encrypt([]byte(util.RandomString(32)), payload, nil)
+	require.NoError(t, err)
+
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	payload, err = maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestTamperedPasetoToken(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), util.CustomerRole, TypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	// replace a character in the middle of the encrypted body
+	i := len(token) / 2
+	replacement := "A"
+	if token[i] == 'A' {
+		replacement = "B"
+	}
+	tampered := token[:i] + replacement + token[i+1:]
+
+	payload, err := maker.VerifyToken(tampered)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestInvalidPasetoKeySize(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(31))
+	require.Error(t, err)
+	require.Nil(t, maker)
+}
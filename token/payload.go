@@ -10,16 +10,27 @@ import (
 var (
 	ErrInvalidToken = errors.New("token is invalid")
 	ErrExpiredToken = errors.New("token has expired")
+	ErrWrongType    = errors.New("token has the wrong type")
+)
+
+// Type tells access tokens, which authorize requests, from refresh tokens, which can only renew them
+type Type string
+
+const (
+	TypeAccess  Type = "access"
+	TypeRefresh Type = "refresh"
 )
 
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
 	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	Type      Type      `json:"token_type"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+func NewPayload(username string, role string, tokenType Type, duration time.Duration) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -27,6 +38,8 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	payload := &Payload{
 		ID:        tokenID,
 		Username:  username,
+		Role:      role,
+		Type:      tokenType,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
@@ -40,3 +53,11 @@ func (payload *Payload) Valid() error {
 	}
 	return nil
 }
+
+// CheckType makes sure that a refresh token isn't used as an access token and vice versa
+func (payload *Payload) CheckType(tokenType Type) error {
+	if payload.Type != tokenType {
+		return ErrWrongType
+	}
+	return nil
+}
@@ -1,17 +1,37 @@
 package util
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	DBDriver            string        `mapstructure:"DB_DRIVER"`
-	DBSource            string        `mapstructure:"DB_SOURCE"`
-	ServerAddress       string        `mapstructure:"SERVER_ADDRESS"`
-	TokenSymmetricKey   string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	DBDriver                  string        `mapstructure:"DB_DRIVER"`
+	DBSource                  string        `mapstructure:"DB_SOURCE"`
+	ServerAddress             string        `mapstructure:"SERVER_ADDRESS"`
+	GRPCServerAddress         string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	SupportedCurrencies       []string      `mapstructure:"SUPPORTED_CURRENCIES"`
+	TokenType                 string        `mapstructure:"TOKEN_TYPE"`
+	TokenSymmetricKey         string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	TokenSigningKeyFile       string        `mapstructure:"TOKEN_SIGNING_KEY_FILE"`
+	TokenVerificationKeyFiles []string      `mapstructure:"TOKEN_VERIFICATION_KEY_FILES"`
+	AccessTokenDuration       time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration      time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	IdempotencyKeyDuration    time.Duration `mapstructure:"IDEMPOTENCY_KEY_DURATION"`
+	RevocationSweepInterval   time.Duration `mapstructure:"REVOCATION_SWEEP_INTERVAL"`
+	FXRateProvider            string        `mapstructure:"FX_RATE_PROVIDER"`
+	FXRatesFile               string        `mapstructure:"FX_RATES_FILE"`
+	FXQuoteDuration           time.Duration `mapstructure:"FX_QUOTE_DURATION"`
+	HoldDuration              time.Duration `mapstructure:"HOLD_DURATION"`
+	HoldExpiryInterval        time.Duration `mapstructure:"HOLD_EXPIRY_INTERVAL"`
+	ScheduledTransferInterval time.Duration `mapstructure:"SCHEDULED_TRANSFER_INTERVAL"`
+	OutboxPublisher           string        `mapstructure:"OUTBOX_PUBLISHER"`
+	OutboxFile                string        `mapstructure:"OUTBOX_FILE"`
+	OutboxWebhookURL          string        `mapstructure:"OUTBOX_WEBHOOK_URL"`
+	OutboxRelayInterval       time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"`
+	WebhookDeliveryInterval   time.Duration `mapstructure:"WEBHOOK_DELIVERY_INTERVAL"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -27,5 +47,32 @@ func LoadConfig(path string) (config Config, err error) {
 	}
 
 	err = viper.Unmarshal(&config)
+	if err != nil {
+		return
+	}
+
+	err = config.validateIntervals()
 	return
 }
+
+// validateIntervals makes sure every background worker is configured with an interval it can tick at.
+// Viper leaves a missing key at zero, which would make time.NewTicker panic once the worker starts.
+func (config Config) validateIntervals() error {
+	intervals := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"REVOCATION_SWEEP_INTERVAL", config.RevocationSweepInterval},
+		{"HOLD_EXPIRY_INTERVAL", config.HoldExpiryInterval},
+		{"SCHEDULED_TRANSFER_INTERVAL", config.ScheduledTransferInterval},
+		{"OUTBOX_RELAY_INTERVAL", config.OutboxRelayInterval},
+		{"WEBHOOK_DELIVERY_INTERVAL", config.WebhookDeliveryInterval},
+	}
+
+	for _, interval := range intervals {
+		if interval.value <= 0 {
+			return fmt.Errorf("%s must be a positive duration, got %s", interval.key, interval.value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigMissingInterval(t *testing.T) {
+	dir := t.TempDir()
+	env := []byte("REVOCATION_SWEEP_INTERVAL=\"1m\"\nHOLD_EXPIRY_INTERVAL=\"1m\"\nSCHEDULED_TRANSFER_INTERVAL=\"1m\"\nOUTBOX_RELAY_INTERVAL=\"1s\"\n")
+	err := os.WriteFile(filepath.Join(dir, "app.env"), env, 0644)
+	require.NoError(t, err)
+
+	_, err = LoadConfig(dir)
+	require.EqualError(t, err, "WEBHOOK_DELIVERY_INTERVAL must be a positive duration, got 0s")
+}
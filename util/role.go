@@ -0,0 +1,7 @@
+package util
+
+const (
+	CustomerRole = "customer"
+	TellerRole   = "teller"
+	AdminRole    = "admin"
+)
@@ -0,0 +1,159 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	db "simple_bank/db/sqlc"
+	"simple_bank/outbox"
+	"strconv"
+	"time"
+)
+
+const (
+	// DeliveryHeader identifies the delivery, which stays the same across retries and replays
+	DeliveryHeader = "X-Webhook-Delivery"
+
+	// MaxAttempts is how many times a delivery is tried before it is marked failed
+	MaxAttempts = 8
+
+	deliveryTimeout   = 10 * time.Second
+	deliveryBatchSize = 50
+	// deliveryLease keeps other deliverers off a claimed delivery while it is being sent
+	deliveryLease = time.Minute
+
+	initialBackoff = 30 * time.Second
+	maxBackoff     = 6 * time.Hour
+)
+
+// Backoff returns how long to wait after the given failed attempt, doubling each time from 30s up to 6h
+func Backoff(attempt int32) time.Duration {
+	backoff := initialBackoff
+	for i := int32(1); i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+// Deliverer sends queued webhook deliveries, retrying failures with exponential backoff
+type Deliverer struct {
+	store  db.Store
+	client *http.Client
+}
+
+func NewDeliverer(store db.Store) *Deliverer {
+	return &Deliverer{
+		store:  store,
+		client: &http.Client{Timeout: deliveryTimeout},
+	}
+}
+
+// DeliverDue sends a batch of the deliveries that are due and returns how many it attempted
+func (deliverer *Deliverer) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, err := deliverer.store.ClaimDueWebhookDeliveries(ctx, db.ClaimDueWebhookDeliveriesParams{
+		LeaseSeconds: int32(deliveryLease / time.Second),
+		PageSize:     deliveryBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		if _, err := deliverer.Deliver(ctx, delivery); err != nil {
+			log.Printf("cannot deliver webhook [%d]: %v", delivery.ID, err)
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// Deliver makes one attempt at sending delivery and records the outcome.
+// It only returns an error if the attempt could not be made or recorded.
+func (deliverer *Deliverer) Deliver(ctx context.Context, delivery db.WebhookDelivery) (db.WebhookDelivery, error) {
+	subscription, err := deliverer.store.GetWebhookSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return delivery, err
+	}
+
+	event, err := deliverer.store.GetOutboxEvent(ctx, delivery.EventID)
+	if err != nil {
+		return delivery, err
+	}
+
+	body, err := json.Marshal(outbox.NewMessage(event))
+	if err != nil {
+		return delivery, err
+	}
+
+	arg := db.RecordWebhookDeliveryAttemptParams{
+		ID:            delivery.ID,
+		Status:        db.WebhookDeliveryStatusSucceeded,
+		NextAttemptAt: time.Now(),
+	}
+
+	status, err := deliverer.send(ctx, subscription, delivery, event, body)
+	if status != 0 {
+		arg.ResponseStatus = &status
+	}
+	if err != nil {
+		arg.LastError = err.Error()
+
+		attempt := delivery.Attempts + 1
+		if attempt >= MaxAttempts {
+			arg.Status = db.WebhookDeliveryStatusFailed
+		} else {
+			arg.Status = db.WebhookDeliveryStatusPending
+			arg.NextAttemptAt = arg.NextAttemptAt.Add(Backoff(attempt))
+		}
+	}
+
+	return deliverer.store.RecordWebhookDeliveryAttempt(ctx, arg)
+}
+
+// send POSTs the signed body and returns the response status, if there was a response
+func (deliverer *Deliverer) send(ctx context.Context, subscription db.WebhookSubscription, delivery db.WebhookDelivery, event db.OutboxEvent, body []byte) (int32, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(outbox.EventIDHeader, strconv.FormatInt(event.ID, 10))
+	request.Header.Set(outbox.EventTypeHeader, event.EventType)
+	request.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	request.Header.Set(SignatureHeader, Sign(subscription.Secret, time.Now(), body))
+
+	response, err := deliverer.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	status := int32(response.StatusCode)
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return status, fmt.Errorf("receiver responded with %s", response.Status)
+	}
+	return status, nil
+}
+
+// Run sends due deliveries every interval until ctx is done
+func (deliverer *Deliverer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := deliverer.DeliverDue(ctx); err != nil {
+				log.Println("cannot deliver webhooks: ", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple_bank/db/mock"
+	db "simple_bank/db/sqlc"
+	"simple_bank/outbox"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, 30*time.Second, Backoff(1))
+	require.Equal(t, time.Minute, Backoff(2))
+	require.Equal(t, 4*time.Minute, Backoff(4))
+	require.Equal(t, maxBackoff, Backoff(20))
+}
+
+func TestDeliver(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+
+	event := db.OutboxEvent{
+		ID:            7,
+		AggregateType: db.AggregateAccount,
+		AggregateID:   "1",
+		EventType:     db.EventAccountCreated,
+		Payload:       json.RawMessage(`{"id":1,"owner":"alice"}`),
+		CreatedAt:     time.Now(),
+	}
+
+	testCases := []struct {
+		name          string
+		attempts      int32
+		status        int
+		checkDelivery func(t *testing.T, arg db.RecordWebhookDeliveryAttemptParams)
+	}{
+		{
+			name:   "OK",
+			status: http.StatusOK,
+			checkDelivery: func(t *testing.T, arg db.RecordWebhookDeliveryAttemptParams) {
+				require.Equal(t, db.WebhookDeliveryStatusSucceeded, arg.Status)
+				require.Equal(t, int32(http.StatusOK), *arg.ResponseStatus)
+				require.Empty(t, arg.LastError)
+			},
+		},
+		{
+			name:     "Retry",
+			attempts: 2,
+			status:   http.StatusServiceUnavailable,
+			checkDelivery: func(t *testing.T, arg db.RecordWebhookDeliveryAttemptParams) {
+				require.Equal(t, db.WebhookDeliveryStatusPending, arg.Status)
+				require.Equal(t, int32(http.StatusServiceUnavailable), *arg.ResponseStatus)
+				require.NotEmpty(t, arg.LastError)
+				require.WithinDuration(t, time.Now().Add(Backoff(3)), arg.NextAttemptAt, time.Second)
+			},
+		},
+		{
+			name:     "GiveUp",
+			attempts: MaxAttempts - 1,
+			status:   http.StatusInternalServerError,
+			checkDelivery: func(t *testing.T, arg db.RecordWebhookDeliveryAttemptParams) {
+				require.Equal(t, db.WebhookDeliveryStatusFailed, arg.Status)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				err = Verify(secret, r.Header.Get(SignatureHeader), body, time.Minute, time.Now())
+				require.NoError(t, err)
+				require.Equal(t, "3", r.Header.Get(DeliveryHeader))
+				require.Equal(t, strconv.FormatInt(event.ID, 10), r.Header.Get(outbox.EventIDHeader))
+
+				var message outbox.Message
+				require.NoError(t, json.Unmarshal(body, &message))
+				require.Equal(t, db.EventAccountCreated, message.Type)
+
+				w.WriteHeader(tc.status)
+			}))
+			defer receiver.Close()
+
+			subscription := db.WebhookSubscription{ID: 1, Username: "alice", Url: receiver.URL, Secret: secret}
+			delivery := db.WebhookDelivery{ID: 3, SubscriptionID: subscription.ID, EventID: event.ID, Attempts: tc.attempts}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+			store.EXPECT().GetOutboxEvent(gomock.Any(), gomock.Eq(event.ID)).Times(1).Return(event, nil)
+			store.EXPECT().
+				RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.RecordWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+					require.Equal(t, delivery.ID, arg.ID)
+					tc.checkDelivery(t, arg)
+					return delivery, nil
+				})
+
+			_, err := NewDeliverer(store).Deliver(context.Background(), delivery)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestDeliverUnreachable(t *testing.T) {
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	url := receiver.URL
+	receiver.Close()
+
+	subscription := db.WebhookSubscription{ID: 1, Url: url, Secret: "secret"}
+	delivery := db.WebhookDelivery{ID: 3, SubscriptionID: subscription.ID, EventID: 7}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Any()).Times(1).Return(subscription, nil)
+	store.EXPECT().GetOutboxEvent(gomock.Any(), gomock.Any()).Times(1).Return(db.OutboxEvent{ID: 7, Payload: json.RawMessage(`{}`)}, nil)
+	store.EXPECT().
+		RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.RecordWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+			require.Equal(t, db.WebhookDeliveryStatusPending, arg.Status)
+			require.Nil(t, arg.ResponseStatus)
+			require.NotEmpty(t, arg.LastError)
+			return delivery, nil
+		})
+
+	_, err := NewDeliverer(store).Deliver(context.Background(), delivery)
+	require.NoError(t, err)
+}
+
+func TestDeliverDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	arg := db.ClaimDueWebhookDeliveriesParams{
+		LeaseSeconds: int32(deliveryLease / time.Second),
+		PageSize:     deliveryBatchSize,
+	}
+	store.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.WebhookDelivery{}, nil)
+
+	n, err := NewDeliverer(store).DeliverDue(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	db "simple_bank/db/sqlc"
+	"simple_bank/outbox"
+)
+
+// EventTypes are the events users can subscribe to
+var EventTypes = []string{
+	db.EventTransferCreated,
+	db.EventAccountCreated,
+	db.EventAccountBalanceUpdated,
+}
+
+// Dispatcher is the outbox publisher that queues a delivery for every subscription to an event.
+// It only queues them; the Deliverer sends them.
+type Dispatcher struct {
+	store db.Store
+}
+
+func NewDispatcher(store db.Store) *Dispatcher {
+	return &Dispatcher{store: store}
+}
+
+// eventParties picks out whose accounts an event is about from any of the payloads in EventTypes
+type eventParties struct {
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+}
+
+// Publish queues the deliveries for message. The outbox may publish a message more than once,
+// but each subscription only gets one delivery per event.
+func (dispatcher *Dispatcher) Publish(ctx context.Context, message outbox.Message) error {
+	if !subscribable(message.Type) {
+		return nil
+	}
+
+	usernames, err := dispatcher.recipients(ctx, message)
+	if err != nil {
+		return err
+	}
+
+	_, err = dispatcher.store.CreateWebhookDeliveries(ctx, db.CreateWebhookDeliveriesParams{
+		EventID:   message.ID,
+		Usernames: usernames,
+		EventType: message.Type,
+	})
+	return err
+}
+
+// recipients returns the owners of the accounts the event is about; both sides of a transfer are told
+func (dispatcher *Dispatcher) recipients(ctx context.Context, message outbox.Message) ([]string, error) {
+	var parties eventParties
+	if err := json.Unmarshal(message.Payload, &parties); err != nil {
+		return nil, err
+	}
+
+	var usernames []string
+	if len(parties.Owner) > 0 {
+		usernames = append(usernames, parties.Owner)
+	}
+
+	for _, accountID := range []int64{parties.FromAccountID, parties.ToAccountID} {
+		if accountID == 0 {
+			continue
+		}
+
+		account, err := dispatcher.store.GetAccount(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		usernames = append(usernames, account.Owner)
+	}
+
+	return usernames, nil
+}
+
+func subscribable(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
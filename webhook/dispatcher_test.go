@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	mockdb "simple_bank/db/mock"
+	db "simple_bank/db/sqlc"
+	"simple_bank/outbox"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcherPublish(t *testing.T) {
+	fromAccount := db.Account{ID: 1, Owner: "alice"}
+	toAccount := db.Account{ID: 2, Owner: "bob"}
+
+	testCases := []struct {
+		name       string
+		message    outbox.Message
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "Transfer",
+			message: outbox.Message{
+				ID:      10,
+				Type:    db.EventTransferCreated,
+				Payload: json.RawMessage(`{"id":5,"from_account_id":1,"to_account_id":2,"amount":10}`),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateWebhookDeliveriesParams{
+					EventID:   10,
+					Usernames: []string{"alice", "bob"},
+					EventType: db.EventTransferCreated,
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().CreateWebhookDeliveries(gomock.Any(), gomock.Eq(arg)).Times(1).Return(int64(2), nil)
+			},
+		},
+		{
+			name: "BalanceUpdated",
+			message: outbox.Message{
+				ID:      11,
+				Type:    db.EventAccountBalanceUpdated,
+				Payload: json.RawMessage(`{"account_id":1,"owner":"alice","balance":90}`),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateWebhookDeliveriesParams{
+					EventID:   11,
+					Usernames: []string{"alice"},
+					EventType: db.EventAccountBalanceUpdated,
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateWebhookDeliveries(gomock.Any(), gomock.Eq(arg)).Times(1).Return(int64(1), nil)
+			},
+		},
+		{
+			name: "NotSubscribable",
+			message: outbox.Message{
+				ID:      12,
+				Type:    db.EventUserCreated,
+				Payload: json.RawMessage(`{"username":"alice"}`),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookDeliveries(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			err := NewDispatcher(store).Publish(context.Background(), tc.message)
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignature(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(secret, secretPrefix))
+
+	body := []byte(`{"id":1}`)
+	now := time.Now()
+	header := Sign(secret, now, body)
+
+	require.NoError(t, Verify(secret, header, body, time.Minute, now))
+
+	otherSecret, err := NewSecret()
+	require.NoError(t, err)
+	require.NotEqual(t, secret, otherSecret)
+
+	testCases := []struct {
+		name    string
+		secret  string
+		header  string
+		body    []byte
+		now     time.Time
+		wantErr error
+	}{
+		{name: "TamperedBody", secret: secret, header: header, body: []byte(`{"id":2}`), now: now, wantErr: ErrInvalidSignature},
+		{name: "WrongSecret", secret: otherSecret, header: header, body: body, now: now, wantErr: ErrInvalidSignature},
+		{name: "Expired", secret: secret, header: header, body: body, now: now.Add(2 * time.Minute), wantErr: ErrSignatureExpired},
+		{name: "MissingSignature", secret: secret, header: "t=1", body: body, now: now, wantErr: ErrInvalidSignature},
+		{name: "Malformed", secret: secret, header: "garbage", body: body, now: now, wantErr: ErrInvalidSignature},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := Verify(tc.secret, tc.header, tc.body, time.Minute, tc.now)
+			require.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}